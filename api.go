@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type ApiJob struct {
+	Id       string     `json:"id"`
+	Name     string     `json:"name,omitempty"`
+	Tags     []string   `json:"tags,omitempty"`
+	Spec     string     `json:"spec"`
+	User     string     `json:"user"`
+	Command  string     `json:"command"`
+	Crontab  string     `json:"crontab"`
+	PrevTime *time.Time `json:"prev,omitempty"`
+	NextTime *time.Time `json:"next,omitempty"`
+	Paused   bool       `json:"paused"`
+}
+
+type ApiError struct {
+	Error string `json:"error"`
+}
+
+// register job control api handlers
+func registerApiHandlers(mux *http.ServeMux) {
+	// GET /api/jobs
+	mux.HandleFunc("/api/jobs", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			apiWriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		runner := currentRunner.Load()
+		if runner == nil {
+			apiWriteError(w, http.StatusServiceUnavailable, fmt.Errorf("runner not ready"))
+			return
+		}
+
+		ret := []ApiJob{}
+		for _, cronjob := range runner.Jobs() {
+			ret = append(ret, runner.apiJob(cronjob))
+		}
+		apiWriteJson(w, http.StatusOK, ret)
+	})
+
+	// POST /api/jobs/{id}/run
+	// POST /api/jobs/{id}/pause
+	// POST /api/jobs/{id}/resume
+	// GET  /api/jobs/{id}/history
+	mux.HandleFunc("/api/jobs/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/jobs/"), "/"), "/")
+		if len(path) != 2 {
+			apiWriteError(w, http.StatusNotFound, fmt.Errorf("unknown api endpoint %s", r.URL.Path))
+			return
+		}
+		jobId, action := path[0], path[1]
+
+		runner := currentRunner.Load()
+		if runner == nil {
+			apiWriteError(w, http.StatusServiceUnavailable, fmt.Errorf("runner not ready"))
+			return
+		}
+
+		switch {
+		case action == "run" && r.Method == http.MethodPost:
+			run, err := runner.Trigger(jobId)
+			if err != nil {
+				apiWriteError(w, http.StatusNotFound, err)
+				return
+			}
+
+			w.Header().Set("Location", fmt.Sprintf("/api/runs/%s", run.Result().Id))
+			apiWriteJobRun(w, r, run)
+		case (action == "pause" || action == "resume") && r.Method == http.MethodPost:
+			_, cronjob := runner.FindJob(jobId)
+			if cronjob == nil {
+				// pause of job which is not found anymore can still be resumed
+				if action == "resume" && pauses.Resume(PAUSE_SCOPE_JOB, jobId) {
+					apiWriteJson(w, http.StatusOK, pauses.List())
+					return
+				}
+
+				apiWriteError(w, http.StatusNotFound, fmt.Errorf("job %s not found", jobId))
+				return
+			}
+
+			apiPauseResume(w, r, action, PAUSE_SCOPE_JOB, pauseJobTarget(cronjob))
+		case action == "history" && r.Method == http.MethodGet:
+			if history == nil {
+				apiWriteError(w, http.StatusNotFound, fmt.Errorf("execution history not enabled (--state-dir)"))
+				return
+			}
+
+			_, cronjob := runner.FindJob(jobId)
+			if cronjob == nil {
+				apiWriteError(w, http.StatusNotFound, fmt.Errorf("job %s not found", jobId))
+				return
+			}
+
+			limit := 0
+			if val := r.URL.Query().Get("limit"); val != "" {
+				var err error
+				if limit, err = strconv.Atoi(val); err != nil {
+					apiWriteError(w, http.StatusBadRequest, fmt.Errorf("invalid limit: %w", err))
+					return
+				}
+			}
+
+			entries, err := history.List(cronjob.Id(), limit)
+			if err != nil {
+				apiWriteError(w, http.StatusInternalServerError, err)
+				return
+			}
+			if entries == nil {
+				entries = []HistoryEntry{}
+			}
+			apiWriteJson(w, http.StatusOK, entries)
+		default:
+			apiWriteError(w, http.StatusNotFound, fmt.Errorf("unknown api endpoint %s %s", r.Method, r.URL.Path))
+		}
+	})
+
+	// POST /api/tags/{tag}/pause
+	// POST /api/tags/{tag}/resume
+	mux.HandleFunc("/api/tags/", func(w http.ResponseWriter, r *http.Request) {
+		path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/tags/"), "/"), "/")
+		if len(path) != 2 || (path[1] != "pause" && path[1] != "resume") || r.Method != http.MethodPost {
+			apiWriteError(w, http.StatusNotFound, fmt.Errorf("unknown api endpoint %s %s", r.Method, r.URL.Path))
+			return
+		}
+
+		apiPauseResume(w, r, path[1], PAUSE_SCOPE_TAG, path[0])
+	})
+
+	// GET  /api/pause (list of active pauses)
+	// POST /api/pause (pause all jobs)
+	mux.HandleFunc("/api/pause", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			apiWriteJson(w, http.StatusOK, pauses.List())
+		case http.MethodPost:
+			apiPauseResume(w, r, "pause", PAUSE_SCOPE_ALL, "")
+		default:
+			apiWriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		}
+	})
+
+	// POST /api/resume (resume all jobs)
+	mux.HandleFunc("/api/resume", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			apiWriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		apiPauseResume(w, r, "resume", PAUSE_SCOPE_ALL, "")
+	})
+
+	// POST /api/reload (reload configuration)
+	mux.HandleFunc("/api/reload", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			apiWriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		// non blocking, a pending reload also covers this request
+		select {
+		case reloadRequests <- "api request":
+		default:
+		}
+		apiWriteJson(w, http.StatusAccepted, struct{}{})
+	})
+
+	// GET /api/runs/{runId}
+	mux.HandleFunc("/api/runs/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			apiWriteError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+			return
+		}
+
+		runId := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/runs/"), "/")
+		run := jobRuns.Get(runId)
+		if run == nil {
+			apiWriteError(w, http.StatusNotFound, fmt.Errorf("run %s not found", runId))
+			return
+		}
+
+		apiWriteJobRun(w, r, run)
+	})
+}
+
+// write run result, optionally waits for the run to finish (?wait=<duration>)
+func apiWriteJobRun(w http.ResponseWriter, r *http.Request, run *JobRun) {
+	if val := r.URL.Query().Get("wait"); val != "" {
+		wait, err := time.ParseDuration(val)
+		if err != nil {
+			apiWriteError(w, http.StatusBadRequest, fmt.Errorf("invalid wait duration: %w", err))
+			return
+		}
+
+		// extend write timeout of server by wait duration (or wait only until write timeout)
+		if opts.Server.WriteTimeout > 0 {
+			err := http.NewResponseController(w).SetWriteDeadline(time.Now().Add(wait + opts.Server.WriteTimeout))
+			if err != nil && wait > opts.Server.WriteTimeout/2 {
+				wait = opts.Server.WriteTimeout / 2
+			}
+		}
+
+		select {
+		case <-run.Done():
+		case <-time.After(wait):
+		case <-r.Context().Done():
+		}
+	}
+
+	select {
+	case <-run.Done():
+		apiWriteJson(w, http.StatusOK, run.Result())
+	default:
+		apiWriteJson(w, http.StatusAccepted, run.Result())
+	}
+}
+
+// pause (optional until ?until=<RFC3339> or ?for=<duration>) or resume scope
+func apiPauseResume(w http.ResponseWriter, r *http.Request, action, scope, target string) {
+	if action == "resume" {
+		if !pauses.Resume(scope, target) {
+			apiWriteError(w, http.StatusNotFound, fmt.Errorf("%s %s is not paused", scope, target))
+			return
+		}
+		apiWriteJson(w, http.StatusOK, pauses.List())
+		return
+	}
+
+	var until time.Time
+	if val := r.URL.Query().Get("until"); val != "" {
+		var err error
+		if until, err = time.Parse(time.RFC3339, val); err != nil {
+			apiWriteError(w, http.StatusBadRequest, fmt.Errorf("invalid until time: %w", err))
+			return
+		}
+	} else if val := r.URL.Query().Get("for"); val != "" {
+		duration, err := time.ParseDuration(val)
+		if err != nil {
+			apiWriteError(w, http.StatusBadRequest, fmt.Errorf("invalid pause duration: %w", err))
+			return
+		}
+		until = time.Now().Add(duration)
+	}
+
+	apiWriteJson(w, http.StatusOK, pauses.Pause(scope, target, until))
+}
+
+func (r *Runner) apiJob(cronjob *CrontabEntry) ApiJob {
+	ret := ApiJob{
+		Id:      cronjob.Id(),
+		Name:    cronjob.Name,
+		Tags:    cronjob.Tags,
+		Spec:    cronjob.Spec,
+		User:    cronjob.User,
+		Command: cronjob.Command,
+		Crontab: cronjob.CrontabPath,
+		Paused:  pauses.Find(cronjob) != nil,
+	}
+
+	entry := r.cron.Entry(cronjob.EntryId)
+	if !entry.Prev.IsZero() {
+		ret.PrevTime = &entry.Prev
+	}
+	if !entry.Next.IsZero() {
+		ret.NextTime = &entry.Next
+	}
+
+	return ret
+}
+
+func apiWriteJson(w http.ResponseWriter, status int, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(data); err != nil {
+		log.Error(err)
+	}
+}
+
+func apiWriteError(w http.ResponseWriter, status int, err error) {
+	apiWriteJson(w, status, ApiError{Error: err.Error()})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestApiJobHistory(t *testing.T) {
+	runner, _, _ := newTestRunner(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Minute)
+	if err := runner.Add(CrontabEntry{Spec: "@hourly", User: "root", Command: "/usr/local/bin/backup", Name: "backup"}); err != nil {
+		t.Fatal(err)
+	}
+	cronjob := runner.Jobs()[0]
+
+	executionHistory, err := NewHistory(t.TempDir(), 0, 0, -1, HISTORY_LOCK_TIMEOUT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer executionHistory.Close()
+	if err := executionHistory.Record(HistoryEntry{JobId: cronjob.Id(), StartTime: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	previousRunner, previousHistory := currentRunner.Swap(runner), history
+	history = executionHistory
+	defer func() {
+		currentRunner.Store(previousRunner)
+		history = previousHistory
+	}()
+
+	mux := http.NewServeMux()
+	registerApiHandlers(mux)
+
+	tests := []struct {
+		job     string
+		status  int
+		entries int
+	}{
+		{cronjob.Id(), http.StatusOK, 1},
+		{"backup", http.StatusOK, 1},
+		{"unknown", http.StatusNotFound, 0},
+	}
+
+	for _, test := range tests {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/jobs/"+test.job+"/history", nil))
+
+		if recorder.Code != test.status {
+			t.Errorf("history of job %s: got status %d, expected %d", test.job, recorder.Code, test.status)
+			continue
+		}
+		if test.status != http.StatusOK {
+			continue
+		}
+
+		var entries []HistoryEntry
+		if err := json.Unmarshal(recorder.Body.Bytes(), &entries); err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != test.entries {
+			t.Errorf("history of job %s: got %d entries, expected %d", test.job, len(entries), test.entries)
+		}
+	}
+}
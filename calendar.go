@@ -0,0 +1,392 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+)
+
+const (
+	// systemd calendar event spec, eg. "@calendar(Mon..Fri *-*-* 09:30:00)"
+	CALENDAR_SPEC = `^@calendar\((.+)\)$`
+)
+
+var (
+	calendarSpecRegex = regexp.MustCompile(CALENDAR_SPEC)
+
+	calendarShorthands = map[string]string{
+		"minutely":     "*-*-* *:*:00",
+		"hourly":       "*-*-* *:00:00",
+		"daily":        "*-*-* 00:00:00",
+		"weekly":       "Mon *-*-* 00:00:00",
+		"monthly":      "*-*-01 00:00:00",
+		"quarterly":    "*-01,04,07,10-01 00:00:00",
+		"semiannually": "*-01,07-01 00:00:00",
+		"yearly":       "*-01-01 00:00:00",
+		"annually":     "*-01-01 00:00:00",
+	}
+
+	calendarWeekdays = map[string]int{
+		"sun": 0, "sunday": 0,
+		"mon": 1, "monday": 1,
+		"tue": 2, "tuesday": 2,
+		"wed": 3, "wednesday": 3,
+		"thu": 4, "thursday": 4,
+		"fri": 5, "friday": 5,
+		"sat": 6, "saturday": 6,
+	}
+)
+
+// Schedule parser for systemd calendar events (OnCalendar=) in "@calendar(...)" specs,
+// other specs are parsed by the wrapped parser
+type CalendarParser struct {
+	parser cron.ScheduleParser
+}
+
+// systemd calendar event ([weekdays] [year-month-day] [hour:minute[:second]] [timezone])
+type CalendarSchedule struct {
+	weekdays map[int]bool
+	years    map[int]bool
+	months   map[int]bool
+	days     map[int]bool
+	hours    map[int]bool
+	minutes  map[int]bool
+	seconds  map[int]bool
+
+	// days are counted from end of month (~)
+	daysFromEnd bool
+
+	location *time.Location
+}
+
+func NewCalendarParser(parser cron.ScheduleParser) CalendarParser {
+	return CalendarParser{parser: parser}
+}
+
+func (p CalendarParser) Parse(spec string) (cron.Schedule, error) {
+	if m := calendarSpecRegex.FindStringSubmatch(strings.TrimSpace(spec)); m != nil {
+		schedule, err := ParseCalendar(m[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid calendar spec \"%s\": %w", spec, err)
+		}
+		return schedule, nil
+	}
+
+	return p.parser.Parse(spec)
+}
+
+// Parse systemd calendar event expression (see systemd.time(7))
+func ParseCalendar(expression string) (*CalendarSchedule, error) {
+	tokens := strings.Fields(expression)
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+
+	// shorthands (eg. daily), optionally followed by timezone
+	if shorthand, exists := calendarShorthands[strings.ToLower(tokens[0])]; exists {
+		tokens = append(strings.Fields(shorthand), tokens[1:]...)
+	}
+
+	schedule := &CalendarSchedule{location: time.Local}
+	weekdayToken, dateToken, timeToken := "", "*-*-*", "00:00:00"
+
+	// weekdays
+	if _, err := parseCalendarWeekdays(tokens[0]); err == nil {
+		weekdayToken = tokens[0]
+		tokens = tokens[1:]
+	}
+
+	// date
+	if len(tokens) >= 1 && !strings.Contains(tokens[0], ":") && strings.ContainsAny(tokens[0], "-~") {
+		dateToken = tokens[0]
+		tokens = tokens[1:]
+	}
+
+	// time
+	if len(tokens) >= 1 && strings.Contains(tokens[0], ":") {
+		timeToken = tokens[0]
+		tokens = tokens[1:]
+	}
+
+	// timezone
+	if len(tokens) >= 1 {
+		location, err := time.LoadLocation(tokens[0])
+		if err != nil {
+			return nil, fmt.Errorf("unknown timezone or invalid token %s", tokens[0])
+		}
+		schedule.location = location
+		tokens = tokens[1:]
+	}
+
+	if len(tokens) >= 1 {
+		return nil, fmt.Errorf("unexpected token %s", tokens[0])
+	}
+
+	var err error
+	if weekdayToken != "" {
+		if schedule.weekdays, err = parseCalendarWeekdays(weekdayToken); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := schedule.parseDate(dateToken); err != nil {
+		return nil, err
+	}
+
+	if err := schedule.parseTime(timeToken); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+// parse date (year-month-day, month-day, or with ~ for days counted from end of month)
+func (s *CalendarSchedule) parseDate(value string) error {
+	separator := "-"
+	if strings.Contains(value, "~") {
+		s.daysFromEnd = true
+		separator = "~"
+	}
+
+	split := strings.LastIndex(value, separator)
+	if split < 0 {
+		return fmt.Errorf("invalid date %s", value)
+	}
+	yearMonth, day := value[:split], value[split+1:]
+
+	year, month := "*", yearMonth
+	if parts := strings.SplitN(yearMonth, "-", 2); len(parts) == 2 {
+		year, month = parts[0], parts[1]
+	}
+
+	var err error
+	if s.years, err = parseCalendarComponent(year, 1970, 2199); err != nil {
+		return fmt.Errorf("invalid year %s: %w", year, err)
+	}
+	if s.months, err = parseCalendarComponent(month, 1, 12); err != nil {
+		return fmt.Errorf("invalid month %s: %w", month, err)
+	}
+	if s.daysFromEnd {
+		s.days, err = parseCalendarDaysFromEnd(day)
+	} else {
+		s.days, err = parseCalendarComponent(day, 1, 31)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid day %s: %w", day, err)
+	}
+	return nil
+}
+
+// parse time (hour:minute[:second], fractions of seconds are ignored)
+func (s *CalendarSchedule) parseTime(value string) error {
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return fmt.Errorf("invalid time %s", value)
+	}
+
+	second := "00"
+	if len(parts) == 3 {
+		second = strings.SplitN(parts[2], ".", 2)[0]
+	}
+
+	var err error
+	if s.hours, err = parseCalendarComponent(parts[0], 0, 23); err != nil {
+		return fmt.Errorf("invalid hour %s: %w", parts[0], err)
+	}
+	if s.minutes, err = parseCalendarComponent(parts[1], 0, 59); err != nil {
+		return fmt.Errorf("invalid minute %s: %w", parts[1], err)
+	}
+	if s.seconds, err = parseCalendarComponent(second, 0, 59); err != nil {
+		return fmt.Errorf("invalid second %s: %w", second, err)
+	}
+	return nil
+}
+
+// Next activation time after t
+func (s *CalendarSchedule) Next(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.location
+	if loc == time.Local {
+		loc = t.Location()
+	}
+	t = t.In(loc)
+
+	// start at next full second
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	end := day.AddDate(SCHEDULE_SEARCH_YEARS, 0, 0)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if !s.dayMatches(day) {
+			continue
+		}
+
+		next := nextTimeOfDay(day, t, calendarBits(s.hours, 23), calendarBits(s.minutes, 59), calendarBits(s.seconds, 59))
+		if !next.IsZero() {
+			return next.In(origLocation)
+		}
+	}
+
+	return time.Time{}
+}
+
+func (s *CalendarSchedule) dayMatches(day time.Time) bool {
+	if s.years != nil && !s.years[day.Year()] {
+		return false
+	}
+
+	if s.months != nil && !s.months[int(day.Month())] {
+		return false
+	}
+
+	if s.weekdays != nil && !s.weekdays[int(day.Weekday())] {
+		return false
+	}
+
+	if s.days != nil {
+		dayOfMonth := day.Day()
+		if s.daysFromEnd {
+			dayOfMonth = daysInMonth(day) - day.Day() + 1
+		}
+		if !s.days[dayOfMonth] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// bitmask of component values (nil sets all values up to max)
+func calendarBits(values map[int]bool, max int) uint64 {
+	var ret uint64
+	for value := 0; value <= max; value++ {
+		if values == nil || values[value] {
+			ret |= 1 << uint(value)
+		}
+	}
+	return ret
+}
+
+// parse component (*, value, start..end, with optional repetition /step; separated by comma), nil for all values
+func parseCalendarComponent(value string, min, max int) (map[int]bool, error) {
+	if value == "*" {
+		return nil, nil
+	}
+
+	ret := map[int]bool{}
+	for _, part := range strings.Split(value, ",") {
+		step := 0
+		if strings.Contains(part, "/") {
+			split := strings.SplitN(part, "/", 2)
+			var err error
+			if step, err = strconv.Atoi(split[1]); err != nil || step <= 0 {
+				return nil, fmt.Errorf("invalid repetition %s", split[1])
+			}
+			part = split[0]
+		}
+
+		start, end := min, max
+		switch {
+		case part == "*":
+		case strings.Contains(part, ".."):
+			split := strings.SplitN(part, "..", 2)
+			var err1, err2 error
+			start, err1 = strconv.Atoi(split[0])
+			end, err2 = strconv.Atoi(split[1])
+			if err1 != nil || err2 != nil {
+				return nil, fmt.Errorf("invalid range %s", part)
+			}
+		default:
+			var err error
+			if start, err = strconv.Atoi(part); err != nil {
+				return nil, fmt.Errorf("invalid value %s", part)
+			}
+			end = start
+			if step > 0 {
+				end = max
+			}
+		}
+
+		if start < min || end > max || start > end {
+			return nil, fmt.Errorf("value %s not inside of %d..%d", part, min, max)
+		}
+
+		if step == 0 {
+			step = 1
+		}
+		for val := start; val <= end; val += step {
+			ret[val] = true
+		}
+	}
+
+	return ret, nil
+}
+
+// parse days counted from end of month, repetitions are counted towards the end of month (eg. ~07/1 for last 7 days)
+func parseCalendarDaysFromEnd(value string) (map[int]bool, error) {
+	ret := map[int]bool{}
+	for _, part := range strings.Split(value, ",") {
+		if !strings.Contains(part, "/") || strings.Contains(part, "..") {
+			values, err := parseCalendarComponent(part, 1, 31)
+			if err != nil {
+				return nil, err
+			}
+			if values == nil {
+				return nil, nil
+			}
+			for val := range values {
+				ret[val] = true
+			}
+			continue
+		}
+
+		split := strings.SplitN(part, "/", 2)
+		start, err := strconv.Atoi(split[0])
+		if err != nil || start < 1 || start > 31 {
+			return nil, fmt.Errorf("invalid value %s", split[0])
+		}
+		step, err := strconv.Atoi(split[1])
+		if err != nil || step <= 0 {
+			return nil, fmt.Errorf("invalid repetition %s", split[1])
+		}
+
+		for val := start; val >= 1; val -= step {
+			ret[val] = true
+		}
+	}
+
+	return ret, nil
+}
+
+// parse weekdays (names, ranges with .. and lists)
+func parseCalendarWeekdays(value string) (map[int]bool, error) {
+	ret := map[int]bool{}
+	for _, part := range strings.Split(value, ",") {
+		split := strings.SplitN(part, "..", 2)
+
+		start, exists := calendarWeekdays[strings.ToLower(split[0])]
+		if !exists {
+			return nil, fmt.Errorf("invalid weekday %s", split[0])
+		}
+
+		end := start
+		if len(split) == 2 {
+			if end, exists = calendarWeekdays[strings.ToLower(split[1])]; !exists {
+				return nil, fmt.Errorf("invalid weekday %s", split[1])
+			}
+		}
+
+		// ranges may wrap around (eg. Sat..Mon), monday is the first day of the week
+		for day := start; ; day = (day + 1) % 7 {
+			ret[day] = true
+			if day == end {
+				break
+			}
+		}
+	}
+	return ret, nil
+}
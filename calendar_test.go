@@ -0,0 +1,107 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCalendarScheduleNext(t *testing.T) {
+	tests := []struct {
+		name       string
+		expression string
+		from       string
+		next       string
+	}{
+		// weekdays
+		{"weekday same day", "Mon..Fri *-*-* 09:30:00", "2026-01-09T08:00:00Z", "2026-01-09T09:30:00Z"},
+		{"weekday after weekend", "Mon..Fri *-*-* 09:30:00", "2026-01-09T10:00:00Z", "2026-01-12T09:30:00Z"},
+		{"weekend list", "Sat,Sun 12:00", "2026-01-05T00:00:00Z", "2026-01-10T12:00:00Z"},
+		{"weekday range wraps", "Sat..Mon 12:00", "2026-01-06T00:00:00Z", "2026-01-10T12:00:00Z"},
+
+		// time zone
+		{"first of month in paris (winter)", "*-*-01 00:00:00 Europe/Paris", "2026-01-15T00:00:00Z", "2026-01-31T23:00:00Z"},
+		{"first of month in paris (summer)", "*-*-01 00:00:00 Europe/Paris", "2026-06-15T00:00:00Z", "2026-06-30T22:00:00Z"},
+
+		// days from end of month
+		{"last day of february leap year", "*-02~01", "2024-01-10T00:00:00Z", "2024-02-29T00:00:00Z"},
+		{"last day of february", "*-02~01", "2026-01-10T00:00:00Z", "2026-02-28T00:00:00Z"},
+		{"third last day", "*-*~03 06:00", "2026-04-01T00:00:00Z", "2026-04-28T06:00:00Z"},
+		{"last monday of may", "Mon *-05~07/1", "2026-01-01T00:00:00Z", "2026-05-25T00:00:00Z"},
+
+		// components
+		{"year and month range", "2027-01..06-* 00:00", "2026-08-01T00:00:00Z", "2027-01-01T00:00:00Z"},
+		{"minute repetition", "*-*-* *:00/15", "2026-01-09T10:07:00Z", "2026-01-09T10:15:00Z"},
+		{"seconds", "*-*-* 10:00:30", "2026-01-09T10:00:00Z", "2026-01-09T10:00:30Z"},
+		{"fraction of seconds is ignored", "*-*-* 10:00:30.5", "2026-01-09T10:00:00Z", "2026-01-09T10:00:30Z"},
+		{"exact time is not next", "*-*-* 10:00", "2026-01-09T10:00:00Z", "2026-01-10T10:00:00Z"},
+
+		// shorthands
+		{"minutely", "minutely", "2026-01-09T10:00:30Z", "2026-01-09T10:01:00Z"},
+		{"hourly", "hourly", "2026-01-09T10:00:00Z", "2026-01-09T11:00:00Z"},
+		{"daily", "daily", "2026-01-09T10:00:00Z", "2026-01-10T00:00:00Z"},
+		{"daily with time zone", "daily Europe/Paris", "2026-01-09T10:00:00Z", "2026-01-09T23:00:00Z"},
+		{"weekly", "weekly", "2026-01-09T10:00:00Z", "2026-01-12T00:00:00Z"},
+		{"monthly", "monthly", "2026-01-09T10:00:00Z", "2026-02-01T00:00:00Z"},
+		{"quarterly", "quarterly", "2026-02-10T00:00:00Z", "2026-04-01T00:00:00Z"},
+		{"semiannually", "semiannually", "2026-02-10T00:00:00Z", "2026-07-01T00:00:00Z"},
+		{"yearly", "yearly", "2026-01-09T10:00:00Z", "2027-01-01T00:00:00Z"},
+		{"annually", "annually", "2026-01-09T10:00:00Z", "2027-01-01T00:00:00Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseCalendar(test.expression)
+			if err != nil {
+				t.Fatalf("parse of %q failed: %v", test.expression, err)
+			}
+
+			from, _ := time.Parse(time.RFC3339, test.from)
+			expected, _ := time.Parse(time.RFC3339, test.next)
+
+			if next := schedule.Next(from); !next.Equal(expected) {
+				t.Errorf("Next(%s) of %q = %s, expected %s", test.from, test.expression, next.UTC().Format(time.RFC3339), test.next)
+			}
+		})
+	}
+}
+
+func TestParseCalendarInvalid(t *testing.T) {
+	for _, expression := range []string{
+		"",
+		"Mon..Foo",
+		"*-13-01",
+		"*-*-32",
+		"*-*-0",
+		"*-*~32",
+		"*-*~00/1",
+		"*-*-*/0",
+		"1969-01-01",
+		"25:00",
+		"*-*-* 12:60",
+		"*-*-* 12:00:61",
+		"*-*-* 12",
+		"*-*-* 12:00 Mars/Olympus",
+		"*-*-* 12:00 UTC trailing",
+		"daily daily",
+	} {
+		if _, err := ParseCalendar(expression); err == nil {
+			t.Errorf("parse of %q should fail", expression)
+		}
+	}
+}
+
+func TestCalendarParser(t *testing.T) {
+	parser := NewCalendarParser(NewQuartzParser(0))
+
+	schedule, err := parser.Parse("@calendar(Mon..Fri *-*-* 09:30:00)")
+	if err != nil {
+		t.Fatalf("parse failed: %v", err)
+	}
+	if _, ok := schedule.(*CalendarSchedule); !ok {
+		t.Errorf("spec not parsed as calendar schedule")
+	}
+
+	if _, err := parser.Parse("@calendar(Mon..Foo)"); err == nil {
+		t.Errorf("parse of invalid calendar spec should fail")
+	}
+}
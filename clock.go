@@ -0,0 +1,180 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock used by runner for execution times and delays (replaceable, eg. for simulation)
+type Clock interface {
+	Now() time.Time
+
+	// Sleep for duration, returns false if stopped before (by closing stop channel)
+	Sleep(duration time.Duration, stop <-chan struct{}) bool
+}
+
+type realClock struct{}
+
+// Virtual clock which is only changed manually, sleeping goroutines are woken when their time is reached
+//
+// Goroutines using the virtual clock have to be started with Go, the clock tracks if they are still active
+// or waiting for a timer (see WaitIdle).
+type VirtualClock struct {
+	lock   sync.Mutex
+	idle   *sync.Cond
+	now    time.Time
+	timers []*VirtualTimer
+	active int
+}
+
+// Timer of virtual clock, receives true if time was reached and false if cancelled
+type VirtualTimer struct {
+	deadline time.Time
+	ch       chan bool
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) Sleep(duration time.Duration, stop <-chan struct{}) bool {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-stop:
+		return false
+	}
+}
+
+func NewVirtualClock(now time.Time) *VirtualClock {
+	c := &VirtualClock{now: now}
+	c.idle = sync.NewCond(&c.lock)
+	return c
+}
+
+// Current virtual time
+func (c *VirtualClock) Now() time.Time {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.now
+}
+
+// Set virtual time (wakes all timers up to this time)
+func (c *VirtualClock) Set(now time.Time) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = now
+	c.fire()
+}
+
+// Advance virtual time by duration
+func (c *VirtualClock) Advance(duration time.Duration) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.now = c.now.Add(duration)
+	c.fire()
+}
+
+// Sleep until virtual time is reached
+func (c *VirtualClock) Sleep(duration time.Duration, stop <-chan struct{}) bool {
+	timer := c.Timer(duration)
+
+	select {
+	case reached := <-timer.ch:
+		return reached
+	case <-stop:
+		c.Cancel(timer)
+		return <-timer.ch
+	}
+}
+
+// Create timer which is reached after duration (calling goroutine is waiting until it receives from timer)
+func (c *VirtualClock) Timer(duration time.Duration) *VirtualTimer {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	timer := &VirtualTimer{deadline: c.now.Add(duration), ch: make(chan bool, 1)}
+	if duration <= 0 {
+		timer.ch <- true
+		return timer
+	}
+
+	c.timers = append(c.timers, timer)
+	sort.SliceStable(c.timers, func(i, j int) bool {
+		return c.timers[i].deadline.Before(c.timers[j].deadline)
+	})
+	c.active--
+	c.idle.Broadcast()
+	return timer
+}
+
+// Cancel timer (waiting goroutine receives false)
+func (c *VirtualClock) Cancel(timer *VirtualTimer) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i, pending := range c.timers {
+		if pending == timer {
+			c.timers = append(c.timers[:i], c.timers[i+1:]...)
+			c.active++
+			timer.ch <- false
+			return
+		}
+	}
+}
+
+// Wait for timer, returns false if timer was cancelled
+func (t *VirtualTimer) Wait() bool {
+	return <-t.ch
+}
+
+// Time of next pending timer
+func (c *VirtualClock) NextTimer() (time.Time, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if len(c.timers) == 0 {
+		return time.Time{}, false
+	}
+	return c.timers[0].deadline, true
+}
+
+// Run function in goroutine tracked by clock
+func (c *VirtualClock) Go(fn func()) {
+	c.lock.Lock()
+	c.active++
+	c.lock.Unlock()
+
+	go func() {
+		defer func() {
+			c.lock.Lock()
+			c.active--
+			c.idle.Broadcast()
+			c.lock.Unlock()
+		}()
+		fn()
+	}()
+}
+
+// Wait until all goroutines of clock are finished or waiting for timers
+func (c *VirtualClock) WaitIdle() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for c.active > 0 {
+		c.idle.Wait()
+	}
+}
+
+// wake timers which are reached (lock must be held)
+func (c *VirtualClock) fire() {
+	for len(c.timers) >= 1 && !c.timers[0].deadline.After(c.now) {
+		timer := c.timers[0]
+		c.timers = c.timers[1:]
+		c.active++
+		timer.ch <- true
+	}
+}
@@ -0,0 +1,105 @@
+package config
+
+import (
+	"fmt"
+	"os"
+
+	flags "github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// key for crontab files in configuration file
+	ConfigFileCrontabsKey = "crontabs"
+)
+
+// Find path of configuration file (--config or $GOCROND_CONFIG) before parsing all options
+func ConfigFilePath(args []string) string {
+	var configOpts struct {
+		Config string `long:"config" env:"GOCROND_CONFIG"`
+	}
+
+	// all other options and errors are handled by the main parser
+	parser := flags.NewParser(&configOpts, flags.IgnoreUnknown)
+	_, _ = parser.ParseArgs(args)
+
+	return configOpts.Config
+}
+
+// Load configuration file (yaml) and use values as defaults of options,
+// keys are long option names (nested mappings are joined with ".", eg. server: {bind: ":8080"}).
+// Returns crontab files of configuration file.
+func LoadConfigFile(parser *flags.Parser, path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read configuration file: %w", err)
+	}
+
+	var root yaml.Node
+	if err := yaml.Unmarshal(content, &root); err != nil {
+		return nil, fmt.Errorf("configuration file %s: %w", path, err)
+	}
+
+	// empty file
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+
+	values := map[string][]string{}
+	lines := map[string]int{}
+	if err := flattenConfigNode(root.Content[0], "", values, lines); err != nil {
+		return nil, fmt.Errorf("configuration file %s: %w", path, err)
+	}
+
+	crontabs := values[ConfigFileCrontabsKey]
+	delete(values, ConfigFileCrontabsKey)
+
+	for name, value := range values {
+		option := parser.FindOptionByLongName(name)
+		if option == nil || name == "config" {
+			return nil, fmt.Errorf("configuration file %s: line %d: unknown option %s", path, lines[name], name)
+		}
+		option.Default = value
+	}
+
+	return crontabs, nil
+}
+
+// flatten yaml mapping to option names and values (scalars or lists of scalars)
+func flattenConfigNode(node *yaml.Node, prefix string, values map[string][]string, lines map[string]int) error {
+	if node.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected mapping", node.Line)
+	}
+
+	for i := 0; i < len(node.Content); i += 2 {
+		key, value := node.Content[i], node.Content[i+1]
+
+		name := key.Value
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		lines[name] = key.Line
+
+		switch value.Kind {
+		case yaml.MappingNode:
+			if err := flattenConfigNode(value, name, values, lines); err != nil {
+				return err
+			}
+		case yaml.SequenceNode:
+			list := []string{}
+			for _, item := range value.Content {
+				if item.Kind != yaml.ScalarNode {
+					return fmt.Errorf("line %d: expected list of values for %s", item.Line, name)
+				}
+				list = append(list, item.Value)
+			}
+			values[name] = list
+		case yaml.ScalarNode:
+			values[name] = []string{value.Value}
+		default:
+			return fmt.Errorf("line %d: invalid value for %s", value.Line, name)
+		}
+	}
+
+	return nil
+}
@@ -13,26 +13,76 @@ type (
 		ShowOnlyVersion bool `long:"dumpversion"              description:"show only version number and exit"`
 		ShowHelp        bool `short:"h"  long:"help"          description:"show this help message"`
 
+		// configuration file (values are used as defaults of options)
+		Config string `long:"config" env:"GOCROND_CONFIG"                  description:"Configuration file (yaml, keys are long option names; precedence: arguments, env vars, configuration file, defaults)"`
+
 		Cron struct {
-			DefaultUser         string   `long:"default-user"         description:"Default user"                  default:"root"`
-			IncludeCronD        []string `long:"include"              description:"Include files in directory as system crontabs (with user)"`
-			Auto                bool     `long:"auto"                 description:"Enable automatic system crontab detection"`
-			RunParts            []string `long:"run-parts"            description:"Execute files in directory with custom spec (like run-parts; spec-units:ns,us,s,m,h; format:time-spec:path; eg:10s,1m,1h30m)"`
-			RunParts1m          []string `long:"run-parts-1min"       description:"Execute files in directory every beginning minute (like run-parts)"`
-			RunParts15m         []string `long:"run-parts-15min"      description:"Execute files in directory every beginning 15 minutes (like run-parts)"`
-			RunPartsHourly      []string `long:"run-parts-hourly"     description:"Execute files in directory every beginning hour (like run-parts)"`
-			RunPartsDaily       []string `long:"run-parts-daily"      description:"Execute files in directory every beginning day (like run-parts)"`
-			RunPartsWeekly      []string `long:"run-parts-weekly"     description:"Execute files in directory every beginning week (like run-parts)"`
-			RunPartsMonthly     []string `long:"run-parts-monthly"    description:"Execute files in directory every beginning month (like run-parts)"`
-			AllowUnprivileged   bool     `long:"allow-unprivileged"   description:"Allow daemon to run as non root (unprivileged) user"`
-			WorkDir             string   `long:"working-directory"    description:"Set the working directory for crontab commands" default:"/"`
+			DefaultUser         string        `long:"default-user"         env:"GOCROND_DEFAULT_USER"            description:"Default user"                  default:"root"`
+			IncludeCronD        []string      `long:"include"              env:"GOCROND_INCLUDE" env-delim:","   description:"Include files in directory as system crontabs (with user)"`
+			SpoolDir            []string      `long:"spool-dir"            env:"GOCROND_SPOOL_DIR" env-delim:"," description:"Include files in spool directory as user crontabs (file name is user, file has to be owned by user; detected with --auto)"`
+			Auto                bool          `long:"auto"                 env:"GOCROND_AUTO"                    description:"Enable automatic system crontab detection"`
+			RunParts            []string      `long:"run-parts"            env:"GOCROND_RUN_PARTS" env-delim:"," description:"Execute files in directory with custom spec (like run-parts; spec-units:ns,us,s,m,h; format:time-spec:path; eg:10s,1m,1h30m)"`
+			RunParts1m          []string      `long:"run-parts-1min"       env:"GOCROND_RUN_PARTS_1MIN" env-delim:"," description:"Execute files in directory every beginning minute (like run-parts)"`
+			RunParts15m         []string      `long:"run-parts-15min"      env:"GOCROND_RUN_PARTS_15MIN" env-delim:"," description:"Execute files in directory every beginning 15 minutes (like run-parts)"`
+			RunPartsHourly      []string      `long:"run-parts-hourly"     env:"GOCROND_RUN_PARTS_HOURLY" env-delim:"," description:"Execute files in directory every beginning hour (like run-parts)"`
+			RunPartsDaily       []string      `long:"run-parts-daily"      env:"GOCROND_RUN_PARTS_DAILY" env-delim:"," description:"Execute files in directory every beginning day (like run-parts)"`
+			RunPartsWeekly      []string      `long:"run-parts-weekly"     env:"GOCROND_RUN_PARTS_WEEKLY" env-delim:"," description:"Execute files in directory every beginning week (like run-parts)"`
+			RunPartsMonthly     []string      `long:"run-parts-monthly"    env:"GOCROND_RUN_PARTS_MONTHLY" env-delim:"," description:"Execute files in directory every beginning month (like run-parts)"`
+			RunPartsSequential  bool          `long:"run-parts-sequential" env:"GOCROND_RUN_PARTS_SEQUENTIAL"    description:"Execute files of run-parts directory one after another in lexical order as one job (like run-parts)"`
+			RunPartsExitOnError bool          `long:"run-parts-exit-on-error" env:"GOCROND_RUN_PARTS_EXIT_ON_ERROR" description:"Stop sequential run-parts execution at first failing file"`
+			RunPartsReport      bool          `long:"run-parts-report"     env:"GOCROND_RUN_PARTS_REPORT"        description:"Prefix output of files in sequential run-parts execution with file name"`
+			AllowUnprivileged   bool          `long:"allow-unprivileged"   env:"GOCROND_ALLOW_UNPRIVILEGED"      description:"Allow daemon to run as non root (unprivileged) user"`
+			WorkDir             string        `long:"working-directory"    env:"GOCROND_WORKING_DIRECTORY"       description:"Set the working directory for crontab commands" default:"/"`
+			PidFile             string        `long:"pidfile"              env:"GOCROND_PIDFILE"                 description:"Write process id of daemon to file (used by crontab command for reloading after changes)"`
+			StateDir            string        `long:"state-dir"            env:"GOCROND_STATE_DIR"               description:"Directory for persistent state (eg. execution history), disabled if empty"`
+			Init                bool          `long:"init"                 env:"GOCROND_INIT"                    description:"Run as init process (reap orphaned zombie processes), enabled automatically if running as PID 1"`
+			ShutdownTimeout     time.Duration `long:"shutdown-timeout"     env:"GOCROND_SHUTDOWN_TIMEOUT"        description:"Time to wait for running jobs after forwarding SIGTERM/SIGINT before killing them" default:"10s"`
+			RunOnce             []string      `long:"run-once"             env:"GOCROND_RUN_ONCE" env-delim:";"  description:"Run selected jobs once and exit with aggregated exit code (selector: all, job id or name, name:<name>, tag:<tag>, crontab:<path>, window:<duration> or window:<from>,<to>)"`
+			Jitter              time.Duration `long:"jitter"               env:"GOCROND_JITTER"                  description:"Maximum random start delay of scheduled jobs (overridden by RANDOM_DELAY crontab variable and jitter annotation)"`
+			JitterDeterministic bool          `long:"jitter-deterministic" env:"GOCROND_JITTER_DETERMINISTIC"    description:"Use a stable start delay per host and job (hash of hostname and job id) instead of a random delay"`
+			HashSalt            string        `long:"hash-salt"            env:"GOCROND_HASH_SALT"               description:"Salt for hashed fields (H) in cron specs (eg. hostname to use different slots on each host)"`
+			DryRun              bool          `long:"dry-run"              env:"GOCROND_DRY_RUN"                 description:"Log resolved commands (user, shell, env, working directory) instead of executing them"`
 			EnableUserSwitching bool
 		}
 
 		// logger
 		Log struct {
 			Verbose bool `short:"v"  long:"verbose"      env:"VERBOSE"  description:"verbose mode"`
-			Json    bool `           long:"log.json"     env:"LOG_JSON" description:"Switch log output to json format"`
+			Json    bool `           long:"log.json"     env:"LOG_JSON"                        description:"Switch log output to json format"`
+		}
+
+		// automatic reload
+		Watch struct {
+			Enabled  bool          `long:"watch"                    env:"GOCROND_WATCH"   description:"Reload automatically if crontabs, include or run-parts directories are changed"`
+			Poll     bool          `long:"watch.poll"               env:"GOCROND_WATCH_POLL" description:"Use polling instead of inotify for watching"`
+			Interval time.Duration `long:"watch.interval"           env:"GOCROND_WATCH_INTERVAL" description:"Polling interval"                            default:"10s"`
+			Debounce time.Duration `long:"watch.debounce"           env:"GOCROND_WATCH_DEBOUNCE" description:"Wait time for further changes before reloading" default:"2s"`
+		}
+
+		// trust policy of crontab and run-parts files
+		File struct {
+			Policy  string   `long:"file.policy"   env:"GOCROND_FILE_POLICY" description:"Trust policy of crontab and run-parts files (strict: owned by root, not writable by group/others, debian file names; current: not writable by group/others; relaxed: not writable by others; custom: --file.owner and --file.mode)" default:"current"`
+			Names   string   `long:"file.names"    env:"GOCROND_FILE_NAMES" description:"Naming rules of files in include and run-parts directories (auto: debian for strict policy, distribution rules with --auto, otherwise all; all, debian, lsb or cronie)" default:"auto"`
+			Owner   []string `long:"file.owner"    env:"GOCROND_FILE_OWNER" env-delim:"," description:"Allowed owners (user names or uids) of files for custom trust policy (default: any)"`
+			Mode    string   `long:"file.mode"     env:"GOCROND_FILE_MODE" description:"Forbidden permission bits (octal) of files for custom trust policy" default:"022"`
+			Include []string `long:"file.include"  env:"GOCROND_FILE_INCLUDE" env-delim:"," description:"Only use files in include and run-parts directories matching glob pattern (file name or path if pattern contains /)"`
+			Exclude []string `long:"file.exclude"  env:"GOCROND_FILE_EXCLUDE" env-delim:"," description:"Ignore files in include and run-parts directories matching glob pattern (file name or path if pattern contains /)"`
+		}
+
+		// remote crontabs (http/https)
+		Remote struct {
+			Interval time.Duration `long:"remote.interval"          env:"GOCROND_REMOTE_INTERVAL" description:"Polling interval of remote crontabs (reload if changed)"  default:"1m"`
+			Timeout  time.Duration `long:"remote.timeout"           env:"GOCROND_REMOTE_TIMEOUT" description:"Timeout for fetching remote crontabs"                   default:"30s"`
+
+			// unencrypted remote crontabs (opt-in)
+			AllowHttp bool `long:"remote.allow-http"  env:"GOCROND_REMOTE_ALLOW_HTTP" description:"Allow remote crontabs by http (unencrypted, only https is allowed by default)"`
+		}
+
+		// execution history
+		History struct {
+			RetentionCount int           `long:"history.retention.count"  env:"GOCROND_HISTORY_RETENTION_COUNT" description:"Number of executions kept per cronjob in history (0 = unlimited)"  default:"100"`
+			RetentionAge   time.Duration `long:"history.retention.age"    env:"GOCROND_HISTORY_RETENTION_AGE" description:"Maximum age of executions kept in history (0 = unlimited)"       default:"720h"`
+			OutputLimit    int           `long:"history.output.limit"     env:"GOCROND_HISTORY_OUTPUT_LIMIT" description:"Maximum size of command output (bytes) kept in history"           default:"4096"`
 		}
 
 		// server settings
@@ -41,11 +91,53 @@ type (
 			ReadTimeout  time.Duration `long:"server.timeout.read"      env:"SERVER_TIMEOUT_READ"   description:"Server read timeout"   default:"5s"`
 			WriteTimeout time.Duration `long:"server.timeout.write"     env:"SERVER_TIMEOUT_WRITE"  description:"Server write timeout"  default:"10s"`
 			Metrics      bool          `long:"server.metrics"           env:"SERVER_METRICS"  description:"Enable prometheus metrics (do not use senstive informations in commands -> use environment variables or files for storing these informations)"`
+			Api          bool          `long:"server.api"               env:"GOCROND_SERVER_API" description:"Enable job control api (/api/...; allows triggering of jobs, only bind to trusted networks)"`
 		}
 
+		// crontab files (remaining arguments)
 		Args struct {
 			Crontabs []string `description:"path to crontab files"`
-		} `positional-args:"yes" `
+		}
+
+		// commands
+		Run struct {
+			Args struct {
+				Job      string   `positional-arg-name:"job" description:"Job id" required:"yes"`
+				Crontabs []string `positional-arg-name:"Crontabs" description:"path to crontab files"`
+			} `positional-args:"yes"`
+		} `command:"run" description:"Run job immediately (same environment as daemon, recorded in history) and exit with its exit code"`
+
+		List struct {
+			Count int    `short:"n" long:"count"  description:"Number of activation times per job (0 = unlimited, requires --to)" default:"5"`
+			From  string `long:"from"             description:"Start of time window (RFC3339, '2006-01-02 15:04' or '15:04'; default: now)"`
+			To    string `long:"to"               description:"End of time window (RFC3339, '2006-01-02 15:04' or '15:04')"`
+			Json  bool   `long:"json"             description:"Output as json"`
+			Args  struct {
+				Crontabs []string `positional-arg-name:"Crontabs" description:"path to crontab files"`
+			} `positional-args:"yes"`
+		} `command:"list" description:"List cronjobs with their next activation times (in local time zone) and exit"`
+
+		Crontab struct {
+			User   string `short:"u" long:"user"    description:"User of crontab (default: current user, other users require root)"`
+			List   bool   `short:"l" long:"list"    description:"Show crontab"`
+			Edit   bool   `short:"e" long:"edit"    description:"Edit crontab with $VISUAL or $EDITOR (default: vi)"`
+			Remove bool   `short:"r" long:"remove"  description:"Remove crontab"`
+			Api    string `long:"api" env:"GOCROND_API_URL" description:"Url of daemon for reloading after changes by job control api (eg. http://127.0.0.1:8080; default: SIGHUP by --pidfile)"`
+			Args   struct {
+				File string `positional-arg-name:"file" description:"Install crontab from file (- for stdin)"`
+			} `positional-args:"yes"`
+		} `command:"crontab" description:"Show, edit, remove or install user crontab of spool directory (like crontab; also used if called as crontab)"`
+
+		Simulate struct {
+			From     string        `long:"from"      description:"Start of simulation (RFC3339, '2006-01-02 15:04' or '15:04'; default: now)"`
+			To       string        `long:"to"        description:"End of simulation (RFC3339, '2006-01-02 15:04' or '15:04'; default: 24h after start)"`
+			Duration time.Duration `long:"duration"  description:"Assumed duration of executions without history"  default:"1m"`
+			History  bool          `long:"history"   description:"Use average durations of successful executions from execution history (--state-dir)"`
+			Json     bool          `long:"json"      description:"Output as json"`
+			Args     struct {
+				Crontabs []string `positional-arg-name:"Crontabs" description:"path to crontab files"`
+			} `positional-args:"yes"`
+		} `command:"simulate" description:"Simulate schedule with a virtual clock (executions, overlaps and peak concurrency) and exit"`
 	}
 )
 
@@ -0,0 +1,294 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const (
+	// name of crontab command (subcommand and busybox style binary name)
+	CRONTAB_COMMAND = "crontab"
+
+	// default editor if neither $VISUAL nor $EDITOR is set
+	CRONTAB_DEFAULT_EDITOR = "vi"
+
+	// timeout for reload request of daemon
+	CRONTAB_RELOAD_TIMEOUT = 10 * time.Second
+)
+
+// crontab client: show, edit, remove or install user crontab of spool directory
+func crontabCommand() {
+	username, err := crontabCommandUser()
+	if err != nil {
+		crontabFatal("%v", err)
+	}
+
+	spoolDir := detectSpoolDir()
+	if len(opts.Cron.SpoolDir) >= 1 {
+		spoolDir = opts.Cron.SpoolDir[0]
+	}
+	if spoolDir == "" {
+		crontabFatal("no spool directory found, use --spool-dir")
+	}
+	path := filepath.Join(spoolDir, username)
+
+	switch {
+	case opts.Crontab.List:
+		content, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			crontabFatal("no crontab for %s", username)
+		} else if err != nil {
+			crontabFatal("%v", err)
+		}
+		os.Stdout.Write(content)
+
+	case opts.Crontab.Remove:
+		if err := os.Remove(path); os.IsNotExist(err) {
+			crontabFatal("no crontab for %s", username)
+		} else if err != nil {
+			crontabFatal("%v", err)
+		}
+		crontabReloadDaemon()
+
+	case opts.Crontab.Edit:
+		content, err := os.ReadFile(path)
+		if err != nil && !os.IsNotExist(err) {
+			crontabFatal("%v", err)
+		}
+
+		edited, err := crontabEdit(path, username, content)
+		if err != nil {
+			crontabFatal("%v", err)
+		}
+		if edited == nil {
+			fmt.Fprintf(os.Stderr, "%s: no changes made to crontab\n", CRONTAB_COMMAND)
+			return
+		}
+
+		crontabInstall(path, username, edited)
+
+	default:
+		var content []byte
+		switch opts.Crontab.Args.File {
+		case "":
+			crontabFatal("file, -l, -e or -r is required")
+		case "-":
+			content, err = io.ReadAll(os.Stdin)
+		default:
+			content, err = os.ReadFile(opts.Crontab.Args.File)
+		}
+		if err != nil {
+			crontabFatal("%v", err)
+		}
+
+		if err := crontabValidate(path, username, content); err != nil {
+			crontabFatal("invalid crontab, not installed:\n%v", err)
+		}
+
+		crontabInstall(path, username, content)
+	}
+}
+
+// user of crontab (other users than current user require root)
+func crontabCommandUser() (string, error) {
+	currentUser, err := user.Current()
+	if err != nil {
+		return "", fmt.Errorf("cannot detect current user: %w", err)
+	}
+
+	if opts.Crontab.User == "" || opts.Crontab.User == currentUser.Username {
+		return currentUser.Username, nil
+	}
+
+	if currentUser.Uid != "0" {
+		return "", fmt.Errorf("must be privileged to use -u")
+	}
+
+	if _, err := user.Lookup(opts.Crontab.User); err != nil {
+		return "", fmt.Errorf("user %s unknown", opts.Crontab.User)
+	}
+	return opts.Crontab.User, nil
+}
+
+// Edit crontab in temporary file with editor until it is valid, returns nil if crontab was not changed
+func crontabEdit(path string, username string, content []byte) ([]byte, error) {
+	editFile, err := os.CreateTemp("", "crontab.")
+	if err != nil {
+		return nil, err
+	}
+	defer os.Remove(editFile.Name())
+
+	_, err = editFile.Write(content)
+	if closeErr := editFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = CRONTAB_DEFAULT_EDITOR
+	}
+
+	stdin := bufio.NewReader(os.Stdin)
+	for {
+		// editor may contain arguments
+		execCmd := exec.Command(DEFAULT_SHELL, "-c", editor+` "$1"`, CRONTAB_COMMAND, editFile.Name())
+		execCmd.Stdin = os.Stdin
+		execCmd.Stdout = os.Stdout
+		execCmd.Stderr = os.Stderr
+		if err := execCmd.Run(); err != nil {
+			return nil, fmt.Errorf("editor %s failed: %w", editor, err)
+		}
+
+		edited, err := os.ReadFile(editFile.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if bytes.Equal(edited, content) {
+			return nil, nil
+		}
+
+		err = crontabValidate(path, username, edited)
+		if err == nil {
+			return edited, nil
+		}
+
+		fmt.Fprintf(os.Stderr, "%s: invalid crontab:\n%v\n", CRONTAB_COMMAND, err)
+		fmt.Fprint(os.Stderr, "Do you want to retry the same edit? (y/n) ")
+		answer, _ := stdin.ReadString('\n')
+		if !strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y") {
+			return nil, fmt.Errorf("invalid crontab not installed")
+		}
+	}
+}
+
+// Validate crontab content with user crontab parser and schedule parser of runner
+func crontabValidate(path string, username string, content []byte) error {
+	parser, err := NewCronjobUserParser(path, username)
+	if err != nil {
+		return err
+	}
+
+	entries, err := parser.ParseReader(bytes.NewReader(content))
+	if err != nil {
+		return err
+	}
+
+	return newRunnerFromOpts().Validate(entries)
+}
+
+// Write crontab atomically to spool directory (owned by user, not readable by others) and reload daemon
+func crontabInstall(path string, username string, content []byte) {
+	fmt.Fprintf(os.Stderr, "%s: installing new crontab\n", CRONTAB_COMMAND)
+
+	// hidden temporary file is ignored by spool directory
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "."+username+".")
+	if err != nil {
+		crontabFatal("%v", err)
+	}
+	tmpPath := tmpFile.Name()
+
+	_, err = tmpFile.Write(content)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, 0600)
+	}
+	if err == nil && os.Geteuid() == 0 {
+		err = crontabChown(tmpPath, username)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		crontabFatal("cannot install crontab: %v", err)
+	}
+
+	crontabReloadDaemon()
+}
+
+// change owner of file to user (and primary group of user)
+func crontabChown(path string, username string) error {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return err
+	}
+
+	uid, err := strconv.Atoi(u.Uid)
+	if err != nil {
+		return err
+	}
+
+	gid, err := strconv.Atoi(u.Gid)
+	if err != nil {
+		return err
+	}
+
+	return os.Chown(path, uid, gid)
+}
+
+// Reload running daemon (job control api or SIGHUP by pidfile)
+func crontabReloadDaemon() {
+	switch {
+	case opts.Crontab.Api != "":
+		client := &http.Client{Timeout: CRONTAB_RELOAD_TIMEOUT}
+		resp, err := client.Post(strings.TrimRight(opts.Crontab.Api, "/")+"/api/reload", "application/json", nil)
+		if err != nil {
+			crontabWarn("cannot reload daemon: %v", err)
+			return
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusAccepted {
+			crontabWarn("cannot reload daemon: unexpected status %s", resp.Status)
+		}
+
+	case opts.Cron.PidFile != "":
+		content, err := os.ReadFile(opts.Cron.PidFile)
+		if err != nil {
+			crontabWarn("cannot reload daemon: %v", err)
+			return
+		}
+
+		pid, err := strconv.Atoi(strings.TrimSpace(string(content)))
+		if err != nil || pid <= 0 {
+			crontabWarn("cannot reload daemon: invalid pidfile %s", opts.Cron.PidFile)
+			return
+		}
+
+		if err := syscall.Kill(pid, syscall.SIGHUP); err != nil {
+			crontabWarn("cannot reload daemon (pid %d): %v", pid, err)
+		}
+
+	default:
+		crontabWarn("daemon not reloaded (use --api or --pidfile, not needed if daemon is running with --watch)")
+	}
+}
+
+func crontabWarn(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "%s: %s\n", CRONTAB_COMMAND, fmt.Sprintf(format, args...))
+}
+
+func crontabFatal(format string, args ...interface{}) {
+	crontabWarn(format, args...)
+	os.Exit(1)
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"os"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// environment variable with system crontab, user crontabs are set as GOCROND_CRONTAB_<USER>
+	CRONTAB_ENV = "GOCROND_CRONTAB"
+
+	// prefix of crontab path for crontabs from environment variables (eg. "env:GOCROND_CRONTAB")
+	CRONTAB_ENV_PATH_PREFIX = "env:"
+)
+
+// Parse crontabs from environment variables (GOCROND_CRONTAB as system crontab,
+// GOCROND_CRONTAB_<USER> as user crontab of lowercase user)
+func includeEnvironmentCrontabs() ([]CrontabEntry, error) {
+	var ret []CrontabEntry
+
+	var names []string
+	for _, env := range os.Environ() {
+		name := strings.SplitN(env, "=", 2)[0]
+		if name == CRONTAB_ENV || strings.HasPrefix(name, CRONTAB_ENV+"_") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		username := CRONTAB_TYPE_SYSTEM
+		if name != CRONTAB_ENV {
+			username = strings.ToLower(strings.TrimPrefix(name, CRONTAB_ENV+"_"))
+			if username == "" {
+				log.Infof("ignoring environment variable %s without user", name)
+				continue
+			}
+		}
+
+		parser, err := newCrontabParser(CRONTAB_ENV_PATH_PREFIX+name, username)
+		if err != nil {
+			return nil, err
+		}
+
+		entries, err := parser.ParseReader(strings.NewReader(os.Getenv(name)))
+		if err != nil {
+			return nil, err
+		}
+
+		log.Debugf("found %d cronjobs in environment variable %s", len(entries), name)
+		ret = append(ret, entries...)
+	}
+
+	return ret, nil
+}
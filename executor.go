@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Executor runs the command of a cronjob (replaceable, eg. for simulation)
+type Executor interface {
+	Execute(cronjob *CrontabEntry, execCmd *exec.Cmd) (exitCode int, output []byte, err error)
+
+	// Kill running command (replaced by newer execution)
+	Kill(execCmd *exec.Cmd) error
+}
+
+// Executes commands as tracked child processes (combined stdout and stderr, killed after timeout of cronjob),
+// dry-run cronjobs (or all cronjobs if DryRun is set) are only logged
+type ProcessExecutor struct {
+	DryRun bool
+}
+
+func (e ProcessExecutor) Execute(cronjob *CrontabEntry, execCmd *exec.Cmd) (int, []byte, error) {
+	if e.DryRun || cronjob.DryRun {
+		return 0, dryRunCommand(cronjob, execCmd), nil
+	}
+
+	var output bytes.Buffer
+	execCmd.Stdout = &output
+	execCmd.Stderr = &output
+	err := processes.RunWithTimeout(execCmd, cronjob.Timeout)
+
+	exitCode := -1
+	if execCmd.ProcessState != nil {
+		exitCode = execCmd.ProcessState.ExitCode()
+	}
+
+	return exitCode, output.Bytes(), err
+}
+
+func (e ProcessExecutor) Kill(execCmd *exec.Cmd) error {
+	return processes.Kill(execCmd)
+}
+
+// log resolved command instead of executing it, returns description as output
+func dryRunCommand(cronjob *CrontabEntry, execCmd *exec.Cmd) []byte {
+	logFields := LogCronjobToFields(*cronjob)
+	logFields["exec"] = strings.Join(execCmd.Args, " ")
+	logFields["env"] = strings.Join(cronjob.Env, " ")
+
+	workDir := execCmd.Dir
+	if workDir == "" {
+		workDir, _ = os.Getwd()
+	}
+	logFields["workdir"] = workDir
+
+	if execCmd.SysProcAttr != nil && execCmd.SysProcAttr.Credential != nil {
+		logFields["uid"] = execCmd.SysProcAttr.Credential.Uid
+		logFields["gid"] = execCmd.SysProcAttr.Credential.Gid
+	}
+
+	log.WithFields(logFields).Infof("dry-run, not executing")
+
+	return []byte(fmt.Sprintf("dry-run: %s (workdir: %s, env: %s)\n", logFields["exec"], workDir, logFields["env"]))
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+const (
+	FILE_NAMES_AUTO   = "auto"
+	FILE_NAMES_ALL    = "all"
+	FILE_NAMES_DEBIAN = "debian"
+	FILE_NAMES_LSB    = "lsb"
+	FILE_NAMES_CRONIE = "cronie"
+)
+
+var (
+	// Debian cron and run-parts (default mode)
+	DEBIAN_FILE_NAME = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	// LSB namespaces (Debian cron -l, run-parts --lsbsysinit)
+	LSB_FILE_NAMES = []*regexp.Regexp{
+		// LANANA-assigned namespace
+		regexp.MustCompile(`^[a-z0-9]+$`),
+		// LSB hierarchical namespace
+		regexp.MustCompile(`^_?([a-z0-9_.]+-)+[a-z0-9]+$`),
+		// Debian cron script namespace
+		DEBIAN_FILE_NAME,
+	}
+	LSB_FILE_NAME_EXCLUDE = regexp.MustCompile(`^[a-zA-Z0-9_.-]+\.dpkg-(old|dist|new|tmp)$`)
+
+	// ignored by cronie (crond and run-parts)
+	CRONIE_FILE_NAME_PREFIXES = []string{".", "#"}
+	CRONIE_FILE_NAME_SUFFIXES = []string{"~", ",v", ".rpmsave", ".rpmorig", ".rpmnew", ".swp", ".cfsaved", ".dpkg-old", ".dpkg-dist", ".dpkg-new", ".dpkg-tmp"}
+)
+
+// Check file name by naming rules (debian, lsb, cronie or all), returns reason if file is ignored
+func checkFileName(rules string, name string) error {
+	// extension of job files is not part of the name
+	if isJobFile(name) {
+		name = strings.TrimSuffix(name, filepath.Ext(name))
+	}
+
+	switch rules {
+	case FILE_NAMES_DEBIAN:
+		if !DEBIAN_FILE_NAME.MatchString(name) {
+			return fmt.Errorf("file name not allowed by debian rules (only letters, digits, underscores and hyphens)")
+		}
+	case FILE_NAMES_LSB:
+		if LSB_FILE_NAME_EXCLUDE.MatchString(name) {
+			return fmt.Errorf("file name not allowed by lsb rules (dpkg leftover)")
+		}
+		for _, regex := range LSB_FILE_NAMES {
+			if regex.MatchString(name) {
+				return nil
+			}
+		}
+		return fmt.Errorf("file name not allowed by lsb rules (not in LANANA, LSB or Debian namespace)")
+	case FILE_NAMES_CRONIE:
+		for _, prefix := range CRONIE_FILE_NAME_PREFIXES {
+			if strings.HasPrefix(name, prefix) {
+				return fmt.Errorf("file name not allowed by cronie rules (prefix %s)", prefix)
+			}
+		}
+		for _, suffix := range CRONIE_FILE_NAME_SUFFIXES {
+			if strings.HasSuffix(name, suffix) {
+				return fmt.Errorf("file name not allowed by cronie rules (suffix %s)", suffix)
+			}
+		}
+	}
+
+	return nil
+}
+
+// Detect naming rules of distribution (used by --auto)
+func detectFileNameRules() string {
+	if checkIfFileExistsAndOwnedByRoot("/etc/debian_version") {
+		return FILE_NAMES_DEBIAN
+	}
+	return FILE_NAMES_CRONIE
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/webdevops/go-crond/config"
+)
+
+const (
+	FILE_POLICY_STRICT  = "strict"
+	FILE_POLICY_CURRENT = "current"
+	FILE_POLICY_RELAXED = "relaxed"
+	FILE_POLICY_CUSTOM  = "custom"
+)
+
+var (
+	// active trust policy (defaults to current behaviour)
+	filePolicy = &FilePolicy{ForbiddenPerm: 0022}
+)
+
+// Trust policy of crontab and run-parts files
+type FilePolicy struct {
+	// allowed owners (uids), any owner if empty
+	Owners []uint32
+
+	// permission bits which must not be set
+	ForbiddenPerm os.FileMode
+
+	// naming rules of files in directories (debian, lsb, cronie or all)
+	NameRules string
+
+	// glob patterns for files in directories
+	Include []string
+	Exclude []string
+}
+
+// Create trust policy from options
+func NewFilePolicy(opts config.Opts) (*FilePolicy, error) {
+	policy := &FilePolicy{
+		Include: opts.File.Include,
+		Exclude: opts.File.Exclude,
+	}
+
+	for _, pattern := range append(append([]string{}, policy.Include...), policy.Exclude...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid file pattern %s: %w", pattern, err)
+		}
+	}
+
+	switch opts.File.Policy {
+	case FILE_POLICY_STRICT:
+		policy.Owners = []uint32{0}
+		policy.ForbiddenPerm = 0022
+	case FILE_POLICY_CURRENT, "":
+		policy.ForbiddenPerm = 0022
+	case FILE_POLICY_RELAXED:
+		policy.ForbiddenPerm = 0002
+	case FILE_POLICY_CUSTOM:
+		perm, err := strconv.ParseUint(opts.File.Mode, 8, 32)
+		if err != nil || perm > 0777 {
+			return nil, fmt.Errorf("invalid file mode %s (expected octal permission bits, eg. 022)", opts.File.Mode)
+		}
+		policy.ForbiddenPerm = os.FileMode(perm)
+
+		for _, owner := range opts.File.Owner {
+			uid, err := lookupUid(owner)
+			if err != nil {
+				return nil, err
+			}
+			policy.Owners = append(policy.Owners, uid)
+		}
+	default:
+		return nil, fmt.Errorf("invalid file policy %s", opts.File.Policy)
+	}
+
+	switch opts.File.Names {
+	case FILE_NAMES_AUTO, "":
+		if opts.File.Policy == FILE_POLICY_STRICT {
+			policy.NameRules = FILE_NAMES_DEBIAN
+		} else if opts.Cron.Auto {
+			policy.NameRules = detectFileNameRules()
+		} else {
+			policy.NameRules = FILE_NAMES_ALL
+		}
+	case FILE_NAMES_ALL, FILE_NAMES_DEBIAN, FILE_NAMES_LSB, FILE_NAMES_CRONIE:
+		policy.NameRules = opts.File.Names
+	default:
+		return nil, fmt.Errorf("invalid file name rules %s", opts.File.Names)
+	}
+
+	return policy, nil
+}
+
+// Check file (already resolved, no symlink), returns reason if file is not trusted
+func (p *FilePolicy) CheckFile(f os.FileInfo) error {
+	if !f.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file")
+	}
+
+	if perm := f.Mode().Perm() & p.ForbiddenPerm; perm != 0 {
+		return fmt.Errorf("wrong modes (%03o, forbidden bits %03o)", f.Mode().Perm(), p.ForbiddenPerm)
+	}
+
+	if len(p.Owners) >= 1 {
+		stat, ok := f.Sys().(*syscall.Stat_t)
+		if !ok {
+			return fmt.Errorf("cannot detect owner")
+		}
+
+		trusted := false
+		for _, uid := range p.Owners {
+			if stat.Uid == uid {
+				trusted = true
+				break
+			}
+		}
+		if !trusted {
+			return fmt.Errorf("untrusted owner (uid %d)", stat.Uid)
+		}
+	}
+
+	return nil
+}
+
+// Check file name of file inside of include or run-parts directory, returns reason if file is ignored
+func (p *FilePolicy) CheckName(path string) error {
+	name := filepath.Base(path)
+
+	if err := checkFileName(p.NameRules, name); err != nil {
+		return err
+	}
+
+	for _, pattern := range p.Exclude {
+		if matchFilePattern(pattern, path) {
+			return fmt.Errorf("excluded by pattern %s", pattern)
+		}
+	}
+
+	if len(p.Include) >= 1 {
+		for _, pattern := range p.Include {
+			if matchFilePattern(pattern, path) {
+				return nil
+			}
+		}
+		return fmt.Errorf("not matched by include patterns")
+	}
+
+	return nil
+}
+
+// match glob pattern against file name (or path if pattern contains a path separator)
+func matchFilePattern(pattern string, path string) bool {
+	subject := filepath.Base(path)
+	if strings.Contains(pattern, string(os.PathSeparator)) {
+		subject = path
+	}
+
+	matched, _ := filepath.Match(pattern, subject)
+	return matched
+}
+
+// lookup uid of user name or numeric uid
+func lookupUid(owner string) (uint32, error) {
+	if uid, err := strconv.ParseUint(owner, 10, 32); err == nil {
+		return uint32(uid), nil
+	}
+
+	u, err := user.Lookup(owner)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file owner %s: %w", owner, err)
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid file owner %s: %w", owner, err)
+	}
+	return uint32(uid), nil
+}
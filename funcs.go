@@ -10,18 +10,27 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func fileGetAbsolutePath(path string) (string, os.FileInfo) {
-	ret, err := filepath.Abs(path)
+// Absolute path, relative paths are resolved from configuration directory
+func absConfPath(path string) (string, error) {
+	if filepath.IsAbs(path) || confDir == "" {
+		return filepath.Abs(path)
+	}
+	return filepath.Join(confDir, path), nil
+}
+
+func fileGetAbsolutePath(path string) (string, os.FileInfo, error) {
+	ret, err := absConfPath(path)
 	if err != nil {
-		log.Fatalf("invalid file: %v", err)
+		return "", nil, fmt.Errorf("invalid file: %w", err)
 	}
 
-	f, err := os.Lstat(ret)
+	// follows symlinks, file checks are done on the target
+	f, err := os.Stat(ret)
 	if err != nil {
-		log.Fatalf("file stats failed: %v", err)
+		return "", nil, fmt.Errorf("file stats failed: %w", err)
 	}
 
-	return ret, f
+	return ret, f, nil
 }
 
 func checkIfDirectoryExists(path string) bool {
@@ -66,15 +75,10 @@ func checkIfFileIsValid(f os.FileInfo, path string) bool {
 		return false
 	}
 
-	if f.Mode().IsRegular() {
-		if f.Mode().Perm()&0022 == 0 {
-			return true
-		} else {
-			log.Infof("ignoring file with wrong modes (not xx22) %s\n", path)
-		}
-	} else {
-		log.Infof("ignoring non regular file %s\n", path)
+	if err := filePolicy.CheckFile(f); err != nil {
+		log.Infof("ignoring file %s: %v\n", path, err)
+		return false
 	}
 
-	return false
+	return true
 }
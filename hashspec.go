@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"hash/fnv"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// hashed field (Jenkins style), eg. "H", "H(0-6)", "H/15" or "H(0-29)/10"
+	HASHED_FIELD = `^H(?:\((\d+)-(\d+)\))?(?:/(\d+))?$`
+)
+
+var (
+	hashedFieldRegex = regexp.MustCompile(HASHED_FIELD)
+
+	// ranges of hashed fields (minute, hour, day of month, month, day of week),
+	// day of month is limited to 28 to run in every month
+	hashedFieldRanges = [][2]int{{0, 59}, {0, 23}, {1, 28}, {1, 12}, {0, 6}}
+)
+
+// Replace hashed fields (H) of spec with stable values derived from identity and salt
+func resolveHashedSpec(spec, identity, salt string) (string, error) {
+	if !strings.Contains(spec, "H") || strings.HasPrefix(spec, "@") {
+		return spec, nil
+	}
+
+	fields := strings.Fields(spec)
+
+	// optional time zone prefix (CRON_TZ=... or TZ=...)
+	offset := 0
+	if len(fields) >= 1 && strings.Contains(fields[0], "=") {
+		offset = 1
+	}
+
+	if len(fields)-offset != len(hashedFieldRanges) {
+		return spec, nil
+	}
+
+	for i, fieldRange := range hashedFieldRanges {
+		var parts []string
+		for _, part := range strings.Split(fields[offset+i], ",") {
+			if !strings.HasPrefix(part, "H") {
+				parts = append(parts, part)
+				continue
+			}
+
+			resolved, err := resolveHashedField(part, fieldRange, fieldHash(identity, salt, i))
+			if err != nil {
+				return spec, fmt.Errorf("invalid hashed field \"%s\" in spec \"%s\": %w", part, spec, err)
+			}
+			parts = append(parts, resolved)
+		}
+		fields[offset+i] = strings.Join(parts, ",")
+	}
+
+	return strings.Join(fields, " "), nil
+}
+
+// Resolve single hashed field inside of range
+func resolveHashedField(field string, fieldRange [2]int, hash uint64) (string, error) {
+	m := hashedFieldRegex.FindStringSubmatch(field)
+	if m == nil {
+		return "", fmt.Errorf("expected H, H(min-max), H/step or H(min-max)/step")
+	}
+
+	min, max := fieldRange[0], fieldRange[1]
+	if m[1] != "" {
+		min, _ = strconv.Atoi(m[1])
+		max, _ = strconv.Atoi(m[2])
+		if min > max || min < fieldRange[0] || max > fieldRange[1] {
+			return "", fmt.Errorf("range %d-%d not inside of %d-%d", min, max, fieldRange[0], fieldRange[1])
+		}
+	}
+
+	// H/step: hashed start inside of first step
+	if m[3] != "" {
+		step, _ := strconv.Atoi(m[3])
+		if step <= 0 {
+			return "", fmt.Errorf("invalid step %d", step)
+		}
+
+		size := step
+		if max-min+1 < size {
+			size = max - min + 1
+		}
+		return fmt.Sprintf("%d-%d/%d", min+int(hash%uint64(size)), max, step), nil
+	}
+
+	return strconv.Itoa(min + int(hash%uint64(max-min+1))), nil
+}
+
+// Stable hash of job identity, salt and field index
+func fieldHash(identity, salt string, field int) uint64 {
+	hash := fnv.New64a()
+	for _, val := range []string{identity, salt, strconv.Itoa(field)} {
+		hash.Write([]byte(val))
+		hash.Write([]byte{0})
+	}
+	return hash.Sum64()
+}
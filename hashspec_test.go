@@ -0,0 +1,165 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	cron "github.com/robfig/cron/v3"
+)
+
+func TestResolveHashedField(t *testing.T) {
+	tests := []struct {
+		name       string
+		field      string
+		fieldRange [2]int
+		min        int
+		max        int
+		step       int
+	}{
+		// minute
+		{"minute H", "H", hashedFieldRanges[0], 0, 59, 0},
+		{"minute H/n", "H/15", hashedFieldRanges[0], 0, 59, 15},
+		{"minute H(a-b)", "H(0-29)", hashedFieldRanges[0], 0, 29, 0},
+		{"minute H(a-b)/n", "H(30-59)/10", hashedFieldRanges[0], 30, 59, 10},
+
+		// hour
+		{"hour H", "H", hashedFieldRanges[1], 0, 23, 0},
+		{"hour H/n", "H/6", hashedFieldRanges[1], 0, 23, 6},
+		{"hour H(a-b)", "H(1-5)", hashedFieldRanges[1], 1, 5, 0},
+		{"hour H(a-b)/n", "H(8-17)/4", hashedFieldRanges[1], 8, 17, 4},
+
+		// day of month
+		{"day of month H", "H", hashedFieldRanges[2], 1, 28, 0},
+		{"day of month H/n", "H/7", hashedFieldRanges[2], 1, 28, 7},
+		{"day of month H(a-b)", "H(1-7)", hashedFieldRanges[2], 1, 7, 0},
+		{"day of month H(a-b)/n", "H(10-20)/5", hashedFieldRanges[2], 10, 20, 5},
+
+		// month
+		{"month H", "H", hashedFieldRanges[3], 1, 12, 0},
+		{"month H/n", "H/3", hashedFieldRanges[3], 1, 12, 3},
+		{"month H(a-b)", "H(6-8)", hashedFieldRanges[3], 6, 8, 0},
+		{"month H(a-b)/n", "H(1-6)/2", hashedFieldRanges[3], 1, 6, 2},
+
+		// day of week
+		{"day of week H", "H", hashedFieldRanges[4], 0, 6, 0},
+		{"day of week H/n", "H/2", hashedFieldRanges[4], 0, 6, 2},
+		{"day of week H(a-b)", "H(1-5)", hashedFieldRanges[4], 1, 5, 0},
+		{"day of week H(a-b)/n step larger than range", "H(5-6)/3", hashedFieldRanges[4], 5, 6, 3},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			for hash := uint64(0); hash < 100; hash++ {
+				resolved, err := resolveHashedField(test.field, test.fieldRange, hash)
+				if err != nil {
+					t.Fatalf("resolve of %q failed: %v", test.field, err)
+				}
+
+				// single value inside of range
+				if test.step == 0 {
+					value, err := strconv.Atoi(resolved)
+					if err != nil || value < test.min || value > test.max {
+						t.Fatalf("resolved %q to %q, expected value inside of %d-%d", test.field, resolved, test.min, test.max)
+					}
+					continue
+				}
+
+				// hashed start inside of first step, until end of range
+				var start, end, step int
+				if _, err := fmt.Sscanf(resolved, "%d-%d/%d", &start, &end, &step); err != nil {
+					t.Fatalf("resolved %q to %q, expected start-end/step", test.field, resolved)
+				}
+				if start < test.min || start >= test.min+test.step || start > test.max || end != test.max || step != test.step {
+					t.Fatalf("resolved %q to %q, expected start inside of %d-%d, end %d and step %d", test.field, resolved, test.min, min(test.min+test.step-1, test.max), test.max, test.step)
+				}
+			}
+		})
+	}
+}
+
+func TestResolveHashedSpec(t *testing.T) {
+	parser := NewQuartzParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+	specs := []string{
+		"H H * * *",
+		"H/15 H(1-5) * * *",
+		"H(0-29)/10 H H/7 H H(1-5)",
+		"0,H 3 * * *",
+		"CRON_TZ=Europe/Berlin H H(1-5) * * *",
+	}
+
+	for _, spec := range specs {
+		resolved, err := resolveHashedSpec(spec, "f0e1d2c3b4a59687", "")
+		if err != nil {
+			t.Errorf("resolve of %q failed: %v", spec, err)
+			continue
+		}
+		if _, err := parser.Parse(resolved); err != nil {
+			t.Errorf("resolved %q to invalid spec %q: %v", spec, resolved, err)
+		}
+
+		// same job id and salt
+		if again, _ := resolveHashedSpec(spec, "f0e1d2c3b4a59687", ""); again != resolved {
+			t.Errorf("resolved %q to %q and %q, expected same spec", spec, resolved, again)
+		}
+	}
+
+	// unchanged specs (no hashed fields or descriptor)
+	for _, spec := range []string{"*/5 * * * *", "@hourly", "@every 1h"} {
+		if resolved, err := resolveHashedSpec(spec, "f0e1d2c3b4a59687", ""); err != nil || resolved != spec {
+			t.Errorf("resolved %q to %q (error: %v), expected unchanged spec", spec, resolved, err)
+		}
+	}
+}
+
+func TestResolveHashedSpecSalt(t *testing.T) {
+	spec := "H H * * *"
+
+	resolved, err := resolveHashedSpec(spec, "f0e1d2c3b4a59687", "host-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	other, err := resolveHashedSpec(spec, "f0e1d2c3b4a59687", "host-b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if other == resolved {
+		t.Errorf("resolved %q to %q with different salts, expected different specs", spec, resolved)
+	}
+
+	otherJob, err := resolveHashedSpec(spec, "0123456789abcdef", "host-a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if otherJob == resolved {
+		t.Errorf("resolved %q to %q for different jobs, expected different specs", spec, resolved)
+	}
+}
+
+func TestResolveHashedSpecInvalid(t *testing.T) {
+	for _, spec := range []string{
+		// out of range
+		"H(0-60) * * * *",
+		"* H(0-24) * * *",
+		"* * H(0-5) * *",
+		"* * H(1-31) * *",
+		"* * * H(0-12) *",
+		"* * * * H(1-7)",
+
+		// inverted range
+		"H(30-10) * * * *",
+		"* * * * H(5-1)",
+
+		// invalid syntax
+		"H/0 * * * *",
+		"H(1-5 * * * *",
+		"Hx * * * *",
+		"H(a-b) * * * *",
+	} {
+		if resolved, err := resolveHashedSpec(spec, "f0e1d2c3b4a59687", ""); err == nil {
+			t.Errorf("resolve of %q should fail, got %q", spec, resolved)
+		}
+	}
+}
@@ -0,0 +1,365 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	HISTORY_DATABASE_FILE = "history.db"
+
+	// interval of retention cleanup of all cronjobs (retention by age)
+	HISTORY_PRUNE_INTERVAL = time.Hour
+
+	// wait for lock of database (previous daemon may still be shutting down)
+	HISTORY_LOCK_TIMEOUT = 5 * time.Second
+
+	// wait for lock of database if it is probably locked by running daemon (read-only access and run command)
+	HISTORY_SHORT_LOCK_TIMEOUT = time.Second
+)
+
+var (
+	historyBucketExecutions = []byte("executions")
+)
+
+type HistoryEntry struct {
+	JobId           string    `json:"jobId"`
+	Spec            string    `json:"spec"`
+	User            string    `json:"user"`
+	Command         string    `json:"command"`
+	CrontabPath     string    `json:"crontab"`
+	Trigger         string    `json:"trigger"`
+	ScheduledTime   time.Time `json:"scheduled"`
+	StartTime       time.Time `json:"start"`
+	EndTime         time.Time `json:"end"`
+	ExitCode        int       `json:"exitCode"`
+	Result          string    `json:"result"`
+	Output          string    `json:"output"`
+	OutputTruncated bool      `json:"outputTruncated,omitempty"`
+}
+
+type History struct {
+	db             *bolt.DB
+	retentionCount int
+	retentionAge   time.Duration
+	outputLimit    int
+
+	// stops periodic retention cleanup (nil if read-only)
+	stop chan struct{}
+	done chan struct{}
+
+	// snapshot copy of database (removed on close)
+	snapshot string
+}
+
+// Open (or create) execution history database inside state directory (waits for lock until timeout)
+func NewHistory(stateDir string, retentionCount int, retentionAge time.Duration, outputLimit int, lockTimeout time.Duration) (*History, error) {
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return nil, fmt.Errorf("cannot create state directory %s: %w", stateDir, err)
+	}
+
+	path := filepath.Join(stateDir, HISTORY_DATABASE_FILE)
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		return nil, fmt.Errorf("cannot open history database %s: %w", path, err)
+	}
+
+	h := &History{
+		db:             db,
+		retentionCount: retentionCount,
+		retentionAge:   retentionAge,
+		outputLimit:    outputLimit,
+		stop:           make(chan struct{}),
+		done:           make(chan struct{}),
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(historyBucketExecutions)
+		return err
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	// cleanup entries of cronjobs which are not executed anymore
+	if err := h.PruneAll(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	go h.pruneLoop(HISTORY_PRUNE_INTERVAL)
+
+	return h, nil
+}
+
+// Open execution history database inside state directory read-only (no retention cleanup),
+// a snapshot copy of the database is used if it is locked by a running daemon
+func OpenHistoryReadOnly(stateDir string) (*History, error) {
+	path := filepath.Join(stateDir, HISTORY_DATABASE_FILE)
+
+	h := &History{}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: HISTORY_SHORT_LOCK_TIMEOUT})
+	if errors.Is(err, bolt.ErrTimeout) {
+		log.Debugf("history database %s is locked, reading snapshot", path)
+		if h.snapshot, err = historySnapshot(path); err == nil {
+			db, err = bolt.Open(h.snapshot, 0600, &bolt.Options{ReadOnly: true})
+			if err != nil {
+				_ = os.Remove(h.snapshot)
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("cannot open history database %s: %w", path, err)
+	}
+	h.db = db
+
+	err = db.View(func(tx *bolt.Tx) error {
+		if tx.Bucket(historyBucketExecutions) == nil {
+			return fmt.Errorf("invalid history database %s", path)
+		}
+		return nil
+	})
+	if err != nil {
+		_ = h.Close()
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// copy database to temporary file
+func historySnapshot(path string) (string, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+
+	dst, err := os.CreateTemp("", "go-crond-history-*.db")
+	if err != nil {
+		return "", err
+	}
+
+	_, err = io.Copy(dst, src)
+	if closeErr := dst.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst.Name())
+		return "", err
+	}
+
+	return dst.Name(), nil
+}
+
+// apply retention to all cronjobs periodically (executions expire by age without new executions)
+func (h *History) pruneLoop(interval time.Duration) {
+	defer close(h.done)
+
+	if h.retentionAge <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			if err := h.PruneAll(); err != nil {
+				log.Errorf("failed to apply history retention: %v", err)
+			}
+		case <-h.stop:
+			return
+		}
+	}
+}
+
+// Record execution of cronjob
+func (h *History) Record(entry HistoryEntry) error {
+	if h.outputLimit >= 0 && len(entry.Output) > h.outputLimit {
+		// do not split multi-byte characters
+		limit := h.outputLimit
+		for limit > 0 && !utf8.RuneStart(entry.Output[limit]) {
+			limit--
+		}
+		entry.Output = entry.Output[:limit]
+		entry.OutputTruncated = true
+	}
+
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	return h.db.Update(func(tx *bolt.Tx) error {
+		bucket, err := tx.Bucket(historyBucketExecutions).CreateBucketIfNotExists([]byte(entry.JobId))
+		if err != nil {
+			return err
+		}
+
+		seq, err := bucket.NextSequence()
+		if err != nil {
+			return err
+		}
+
+		if err := bucket.Put(historyKey(entry.StartTime, seq), value); err != nil {
+			return err
+		}
+
+		return h.prune(bucket)
+	})
+}
+
+// Average duration of successful executions of cronjob (and number of executions used)
+func (h *History) AverageDuration(jobId string) (time.Duration, int, error) {
+	entries, err := h.List(jobId, 0)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var total time.Duration
+	count := 0
+	for _, entry := range entries {
+		if entry.Result == JOB_RESULT_SUCCESS && !entry.EndTime.IsZero() {
+			total += entry.EndTime.Sub(entry.StartTime)
+			count++
+		}
+	}
+
+	if count == 0 {
+		return 0, 0, nil
+	}
+	return total / time.Duration(count), count, nil
+}
+
+// List executions of cronjob (newest first), limit <= 0 returns all executions
+func (h *History) List(jobId string, limit int) ([]HistoryEntry, error) {
+	var ret []HistoryEntry
+
+	err := h.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(historyBucketExecutions).Bucket([]byte(jobId))
+		if bucket == nil {
+			return nil
+		}
+
+		c := bucket.Cursor()
+		for k, v := c.Last(); k != nil; k, v = c.Prev() {
+			if limit > 0 && len(ret) >= limit {
+				break
+			}
+
+			var entry HistoryEntry
+			if err := json.Unmarshal(v, &entry); err != nil {
+				return fmt.Errorf("invalid history entry for job %s: %w", jobId, err)
+			}
+			ret = append(ret, entry)
+		}
+		return nil
+	})
+
+	return ret, err
+}
+
+// Apply retention to all cronjobs
+func (h *History) PruneAll() error {
+	return h.db.Update(func(tx *bolt.Tx) error {
+		root := tx.Bucket(historyBucketExecutions)
+
+		var emptyBuckets [][]byte
+		err := root.ForEachBucket(func(name []byte) error {
+			bucket := root.Bucket(name)
+			if err := h.prune(bucket); err != nil {
+				return err
+			}
+
+			if k, _ := bucket.Cursor().First(); k == nil {
+				emptyBuckets = append(emptyBuckets, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, name := range emptyBuckets {
+			if err := root.DeleteBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close history database
+func (h *History) Close() error {
+	if h.stop != nil {
+		close(h.stop)
+		<-h.done
+	}
+
+	err := h.db.Close()
+	if h.snapshot != "" {
+		_ = os.Remove(h.snapshot)
+	}
+	return err
+}
+
+// remove executions exceeding retention count and age
+func (h *History) prune(bucket *bolt.Bucket) error {
+	c := bucket.Cursor()
+
+	// retention by age (keys are sorted by start time)
+	if h.retentionAge > 0 {
+		cutoff := time.Now().Add(-h.retentionAge)
+		for k, _ := c.First(); k != nil; k, _ = c.First() {
+			if !historyKeyTime(k).Before(cutoff) {
+				break
+			}
+			if err := c.Delete(); err != nil {
+				return err
+			}
+		}
+	}
+
+	// retention by count
+	if h.retentionCount > 0 {
+		count := 0
+		for k, _ := c.Last(); k != nil; k, _ = c.Prev() {
+			count++
+		}
+
+		for k, _ := c.First(); k != nil && count > h.retentionCount; k, _ = c.First() {
+			if err := c.Delete(); err != nil {
+				return err
+			}
+			count--
+		}
+	}
+
+	return nil
+}
+
+// build sortable history key (start time + sequence)
+func historyKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, 16)
+	binary.BigEndian.PutUint64(key[0:8], uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:16], seq)
+	return key
+}
+
+func historyKeyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key[0:8])))
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestHistoryOutputTruncation(t *testing.T) {
+	// "ä" is encoded as two bytes, limit of 5 bytes would split the third character
+	history, err := NewHistory(t.TempDir(), 0, 0, 5, HISTORY_LOCK_TIMEOUT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer history.Close()
+
+	if err := history.Record(HistoryEntry{JobId: "job", StartTime: time.Now(), Output: strings.Repeat("ä", 10)}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := history.List("job", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 {
+		t.Fatalf("got %d entries, expected 1", len(entries))
+	}
+
+	entry := entries[0]
+	if entry.Output != "ää" || !entry.OutputTruncated {
+		t.Errorf("got output %q (truncated: %v), expected %q (truncated: true)", entry.Output, entry.OutputTruncated, "ää")
+	}
+	if !utf8.ValidString(entry.Output) {
+		t.Errorf("truncated output %q is not valid utf-8", entry.Output)
+	}
+}
+
+func TestHistoryPruneLoop(t *testing.T) {
+	history, err := NewHistory(t.TempDir(), 0, 0, -1, HISTORY_LOCK_TIMEOUT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer history.Close()
+
+	// executions of cronjob which is not executed anymore and of active cronjob
+	if err := history.Record(HistoryEntry{JobId: "expired", StartTime: time.Now().Add(-30 * time.Minute)}); err != nil {
+		t.Fatal(err)
+	}
+	if err := history.Record(HistoryEntry{JobId: "active", StartTime: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// restart retention cleanup (without age retention it is not running)
+	<-history.done
+	history.retentionAge = 10 * time.Minute
+	history.done = make(chan struct{})
+	go history.pruneLoop(10 * time.Millisecond)
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		entries, err := history.List("expired", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) == 0 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("got %d entries of expired job, expected 0", len(entries))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if entries, _ := history.List("active", 0); len(entries) != 1 {
+		t.Errorf("got %d entries of active job, expected 1", len(entries))
+	}
+}
+
+func TestHistoryReadOnly(t *testing.T) {
+	stateDir := t.TempDir()
+
+	history, err := NewHistory(stateDir, 0, 0, -1, HISTORY_LOCK_TIMEOUT)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := history.Record(HistoryEntry{JobId: "job", StartTime: time.Now().Add(-time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	// database locked by running daemon (snapshot is read)
+	readOnly, err := OpenHistoryReadOnly(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := readOnly.List("job", 0); err != nil || len(entries) != 1 {
+		t.Errorf("got %d entries (error: %v) from snapshot, expected 1", len(entries), err)
+	}
+	if err := readOnly.Record(HistoryEntry{JobId: "job", StartTime: time.Now()}); err == nil {
+		t.Errorf("record into read-only history should fail")
+	}
+
+	snapshot := readOnly.snapshot
+	if snapshot == "" {
+		t.Errorf("locked database should be read from snapshot")
+	}
+	if err := readOnly.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(snapshot); !os.IsNotExist(err) {
+		t.Errorf("snapshot %s not removed on close", snapshot)
+	}
+
+	if err := history.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// unlocked database
+	readOnly, err = OpenHistoryReadOnly(stateDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer readOnly.Close()
+
+	if readOnly.snapshot != "" {
+		t.Errorf("unlocked database should not be read from snapshot")
+	}
+	if entries, err := readOnly.List("job", 0); err != nil || len(entries) != 1 {
+		t.Errorf("got %d entries (error: %v), expected 1", len(entries), err)
+	}
+
+	if _, err := OpenHistoryReadOnly(t.TempDir()); err == nil {
+		t.Errorf("read-only open of missing database should fail")
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Job file with structured job definitions (yaml)
+type JobFile struct {
+	Jobs []JobDefinition `yaml:"jobs"`
+}
+
+// Job definition of job file, mapped to CrontabEntry
+type JobDefinition struct {
+	Name              string            `yaml:"name"`
+	Schedule          string            `yaml:"schedule"`
+	Command           string            `yaml:"command"`
+	User              string            `yaml:"user"`
+	Shell             string            `yaml:"shell"`
+	Timeout           time.Duration     `yaml:"timeout"`
+	Retries           int               `yaml:"retries"`
+	ConcurrencyPolicy string            `yaml:"concurrencyPolicy"`
+	Env               map[string]string `yaml:"env"`
+	WorkingDir        string            `yaml:"workingDir"`
+	Tags              []string          `yaml:"tags"`
+	Jitter            *time.Duration    `yaml:"jitter"`
+	DryRun            bool              `yaml:"dryRun"`
+	Notifications     []Notification    `yaml:"notifications"`
+
+	// line of definition in job file
+	line int
+}
+
+// Check if path is a job file (by file extension)
+func isJobFile(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+// Parse job file, all invalid job definitions are reported (with line numbers)
+func parseJobFile(path string, username string) ([]CrontabEntry, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("job file path: %v err: %w", path, err)
+	}
+	defer reader.Close()
+
+	return parseJobFileReader(reader, path, username)
+}
+
+// Parse job file content from reader (path is used as crontab path of entries)
+func parseJobFileReader(reader io.Reader, path string, username string) ([]CrontabEntry, error) {
+	var jobFile JobFile
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&jobFile); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("job file %s: %w", path, err)
+	}
+
+	var (
+		ret   []CrontabEntry
+		errs  []error
+		names = map[string]int{}
+	)
+	for _, job := range jobFile.Jobs {
+		if job.Name != "" {
+			if line, exists := names[job.Name]; exists {
+				errs = append(errs, fmt.Errorf("%s:%d: duplicate job name %s (first defined at line %d)", path, job.line, job.Name, line))
+				continue
+			}
+			names[job.Name] = job.line
+		}
+
+		if jobErrs := job.Validate(username); len(jobErrs) >= 1 {
+			for _, err := range jobErrs {
+				errs = append(errs, fmt.Errorf("%s:%d: %w", path, job.line, err))
+			}
+			continue
+		}
+		ret = append(ret, job.CrontabEntry(path, username))
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (job *JobDefinition) UnmarshalYAML(value *yaml.Node) error {
+	if err := checkYamlFields(value, job); err != nil {
+		return err
+	}
+
+	type plain JobDefinition
+	if err := value.Decode((*plain)(job)); err != nil {
+		return err
+	}
+	job.line = value.Line
+	return nil
+}
+
+func (n *Notification) UnmarshalYAML(value *yaml.Node) error {
+	if err := checkYamlFields(value, n); err != nil {
+		return err
+	}
+
+	type plain Notification
+	return value.Decode((*plain)(n))
+}
+
+// Validate job definition (schedule is validated by runner)
+func (job *JobDefinition) Validate(username string) []error {
+	var errs []error
+
+	if strings.TrimSpace(job.Schedule) == "" {
+		errs = append(errs, fmt.Errorf("schedule is required"))
+	}
+
+	if strings.TrimSpace(job.Command) == "" {
+		errs = append(errs, fmt.Errorf("command is required"))
+	}
+
+	if username != CRONTAB_TYPE_SYSTEM && job.User != "" && job.User != username {
+		errs = append(errs, fmt.Errorf("user %s is not allowed in job file of user %s", job.User, username))
+	}
+
+	if job.Timeout < 0 {
+		errs = append(errs, fmt.Errorf("timeout must not be negative"))
+	}
+
+	if job.Retries < 0 {
+		errs = append(errs, fmt.Errorf("retries must not be negative"))
+	}
+
+	if job.Jitter != nil && *job.Jitter < 0 {
+		errs = append(errs, fmt.Errorf("jitter must not be negative"))
+	}
+
+	switch job.ConcurrencyPolicy {
+	case "", CONCURRENCY_POLICY_ALLOW, CONCURRENCY_POLICY_FORBID, CONCURRENCY_POLICY_REPLACE:
+	default:
+		errs = append(errs, fmt.Errorf("invalid concurrencyPolicy %s (allowed: %s, %s, %s)", job.ConcurrencyPolicy, CONCURRENCY_POLICY_ALLOW, CONCURRENCY_POLICY_FORBID, CONCURRENCY_POLICY_REPLACE))
+	}
+
+	for i, notification := range job.Notifications {
+		switch notification.On {
+		case "", NOTIFY_ON_FAILURE, NOTIFY_ON_SUCCESS, NOTIFY_ON_ALWAYS:
+		default:
+			errs = append(errs, fmt.Errorf("notification %d: invalid on %s (allowed: %s, %s, %s)", i+1, notification.On, NOTIFY_ON_FAILURE, NOTIFY_ON_SUCCESS, NOTIFY_ON_ALWAYS))
+		}
+
+		if (notification.Webhook == "") == (notification.Command == "") {
+			errs = append(errs, fmt.Errorf("notification %d: either webhook or command is required", i+1))
+		}
+	}
+
+	return errs
+}
+
+// Create crontab entry of (valid) job definition
+func (job *JobDefinition) CrontabEntry(path string, username string) CrontabEntry {
+	user := username
+	if username == CRONTAB_TYPE_SYSTEM {
+		user = job.User
+		if user == "" {
+			user = opts.Cron.DefaultUser
+		}
+	}
+
+	shell := job.Shell
+	if shell == "" {
+		shell = DEFAULT_SHELL
+	}
+
+	var env []string
+	for name, value := range job.Env {
+		env = append(env, fmt.Sprintf("%s=%s", name, value))
+	}
+	sort.Strings(env)
+
+	return CrontabEntry{
+		Spec:              strings.Join(strings.Fields(job.Schedule), " "),
+		User:              user,
+		Command:           strings.TrimSpace(job.Command),
+		Env:               env,
+		Shell:             shell,
+		CrontabPath:       path,
+		CrontabLine:       job.line,
+		Name:              job.Name,
+		Tags:              job.Tags,
+		DryRun:            job.DryRun,
+		Jitter:            job.Jitter,
+		WorkDir:           job.WorkingDir,
+		Timeout:           job.Timeout,
+		Retries:           job.Retries,
+		ConcurrencyPolicy: job.ConcurrencyPolicy,
+		Notifications:     job.Notifications,
+	}
+}
+
+// Check yaml mapping for unknown fields (by yaml tags of struct)
+func checkYamlFields(value *yaml.Node, v interface{}) error {
+	if value.Kind != yaml.MappingNode {
+		return fmt.Errorf("line %d: expected mapping", value.Line)
+	}
+
+	fields := map[string]bool{}
+	structType := reflect.TypeOf(v).Elem()
+	for i := 0; i < structType.NumField(); i++ {
+		if tag := strings.Split(structType.Field(i).Tag.Get("yaml"), ",")[0]; tag != "" {
+			fields[tag] = true
+		}
+	}
+
+	for i := 0; i < len(value.Content); i += 2 {
+		key := value.Content[i]
+		if !fields[key.Value] {
+			return fmt.Errorf("line %d: unknown field %s", key.Line, key.Value)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+const (
+	JOB_TRIGGER_SCHEDULE = "schedule"
+	JOB_TRIGGER_MANUAL   = "manual"
+	JOB_TRIGGER_RUN_ONCE = "run-once"
+
+	JOB_RESULT_PENDING = "pending"
+	JOB_RESULT_RUNNING = "running"
+	JOB_RESULT_SUCCESS = "success"
+	JOB_RESULT_ERROR   = "error"
+
+	// number of manual runs kept in memory for fetching results
+	JOB_RUN_REGISTRY_LIMIT = 100
+)
+
+type JobRunResult struct {
+	Id            string    `json:"id"`
+	JobId         string    `json:"jobId"`
+	Trigger       string    `json:"trigger"`
+	ScheduledTime time.Time `json:"scheduled"`
+	StartTime     time.Time `json:"start"`
+	EndTime       time.Time `json:"end"`
+	ExitCode      int       `json:"exitCode"`
+	Result        string    `json:"result"`
+	Output        string    `json:"output"`
+}
+
+// Single execution of a cronjob
+type JobRun struct {
+	lock   sync.Mutex
+	result JobRunResult
+	done   chan struct{}
+}
+
+type JobRunRegistry struct {
+	lock  sync.Mutex
+	runs  map[string]*JobRun
+	order []string
+	limit int
+}
+
+var (
+	jobRuns = &JobRunRegistry{runs: map[string]*JobRun{}, limit: JOB_RUN_REGISTRY_LIMIT}
+)
+
+func NewJobRun(cronjob *CrontabEntry, trigger string, scheduled time.Time) *JobRun {
+	return &JobRun{
+		result: JobRunResult{
+			Id:            newJobRunId(),
+			JobId:         cronjob.Id(),
+			Trigger:       trigger,
+			ScheduledTime: scheduled,
+			ExitCode:      -1,
+			Result:        JOB_RESULT_PENDING,
+		},
+		done: make(chan struct{}),
+	}
+}
+
+// Current state of run
+func (run *JobRun) Result() JobRunResult {
+	run.lock.Lock()
+	defer run.lock.Unlock()
+	return run.result
+}
+
+// Channel closed when run is finished
+func (run *JobRun) Done() <-chan struct{} {
+	return run.done
+}
+
+func (run *JobRun) start(start time.Time) {
+	run.lock.Lock()
+	defer run.lock.Unlock()
+
+	run.result.StartTime = start
+	if run.result.ScheduledTime.IsZero() {
+		run.result.ScheduledTime = start
+	}
+	run.result.Result = JOB_RESULT_RUNNING
+}
+
+func (run *JobRun) finish(end time.Time, exitCode int, result string, output []byte) {
+	run.lock.Lock()
+	run.result.EndTime = end
+	run.result.ExitCode = exitCode
+	run.result.Result = result
+	run.result.Output = string(output)
+	run.lock.Unlock()
+
+	close(run.done)
+}
+
+// Add run to registry (oldest runs are removed if limit is reached)
+func (reg *JobRunRegistry) Add(run *JobRun) {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	id := run.result.Id
+	reg.runs[id] = run
+	reg.order = append(reg.order, id)
+
+	for len(reg.order) > reg.limit {
+		delete(reg.runs, reg.order[0])
+		reg.order = reg.order[1:]
+	}
+}
+
+// Get run from registry
+func (reg *JobRunRegistry) Get(id string) *JobRun {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+	return reg.runs[id]
+}
+
+func newJobRunId() string {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return time.Now().Format("20060102150405.000000000")
+	}
+	return hex.EncodeToString(buf)
+}
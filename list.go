@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	LIST_TIME_FORMAT = "2006-01-02 15:04:05 MST"
+)
+
+type ListJob struct {
+	Id           string      `json:"id"`
+	Name         string      `json:"name,omitempty"`
+	Tags         []string    `json:"tags,omitempty"`
+	Spec         string      `json:"spec"`
+	ResolvedSpec string      `json:"resolvedSpec,omitempty"`
+	User         string      `json:"user"`
+	Command      string      `json:"command"`
+	Crontab      string      `json:"crontab"`
+	CrontabLine  int         `json:"line,omitempty"`
+	Next         []time.Time `json:"next"`
+}
+
+// list cronjobs with their next activation times and exit
+func listCommand() {
+	now := time.Now()
+
+	from := now
+	if opts.List.From != "" {
+		var err error
+		if from, err = parseListTime(opts.List.From, now); err != nil {
+			log.Fatalf("invalid --from: %v", err)
+		}
+	}
+
+	var to time.Time
+	if opts.List.To != "" {
+		var err error
+		if to, err = parseListTime(opts.List.To, from); err != nil {
+			log.Fatalf("invalid --to: %v", err)
+		}
+	}
+
+	if opts.List.Count <= 0 && to.IsZero() {
+		log.Fatal("--count 0 (unlimited) requires --to")
+	}
+
+	runner := newRunnerFromOpts()
+	crontabEntries := loadCommandCrontabs(runner, opts.List.Args.Crontabs)
+
+	ret := []ListJob{}
+	for _, cronjob := range crontabEntries {
+		schedule, _ := runner.Schedule(&cronjob)
+		resolvedSpec, _ := runner.resolveSpec(&cronjob)
+		if resolvedSpec == cronjob.Spec {
+			resolvedSpec = ""
+		}
+
+		job := ListJob{
+			Id:           cronjob.Id(),
+			Name:         cronjob.Name,
+			Tags:         cronjob.Tags,
+			Spec:         cronjob.Spec,
+			ResolvedSpec: resolvedSpec,
+			User:         cronjob.User,
+			Command:      cronjob.Command,
+			Crontab:      cronjob.CrontabPath,
+			CrontabLine:  cronjob.CrontabLine,
+			Next:         scheduleActivations(schedule, from, to, opts.List.Count),
+		}
+
+		// only jobs running inside of the time window
+		if !to.IsZero() && len(job.Next) == 0 {
+			continue
+		}
+
+		ret = append(ret, job)
+	}
+
+	// order by next activation
+	sort.SliceStable(ret, func(i, j int) bool {
+		if len(ret[i].Next) == 0 || len(ret[j].Next) == 0 {
+			return len(ret[i].Next) > len(ret[j].Next)
+		}
+		return ret[i].Next[0].Before(ret[j].Next[0])
+	})
+
+	if opts.List.Json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(ret); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "ID\tNAME\tUSER\tSOURCE\tSPEC\tNEXT\tCOMMAND")
+	for _, job := range ret {
+		source := job.Crontab
+		if source == "" {
+			source = "run-parts"
+		} else if job.CrontabLine > 0 {
+			source = fmt.Sprintf("%s:%d", job.Crontab, job.CrontabLine)
+		}
+
+		spec := job.Spec
+		if job.ResolvedSpec != "" {
+			spec = fmt.Sprintf("%s (%s)", job.Spec, job.ResolvedSpec)
+		}
+
+		next := "-"
+		if len(job.Next) >= 1 {
+			next = job.Next[0].Format(LIST_TIME_FORMAT)
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", job.Id, job.Name, job.User, source, spec, next, job.Command)
+
+		// further activations below first line
+		for _, next := range job.Next[min(len(job.Next), 1):] {
+			fmt.Fprintf(writer, "\t\t\t\t\t%s\t\n", next.Format(LIST_TIME_FORMAT))
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// activation times of schedule inside of time window (zero to: unlimited), limit <= 0 returns all activations
+func scheduleActivations(schedule cron.Schedule, from, to time.Time, limit int) []time.Time {
+	ret := []time.Time{}
+
+	// start one nanosecond before window to include activations at the start of the window
+	next := from.Add(-time.Nanosecond)
+	for limit <= 0 || len(ret) < limit {
+		next = schedule.Next(next)
+		if next.IsZero() || (!to.IsZero() && !next.Before(to)) {
+			break
+		}
+		ret = append(ret, next)
+	}
+
+	return ret
+}
+
+// parse time (RFC3339, date with time or time of day), time of day is the next occurrence after base
+func parseListTime(value string, base time.Time) (time.Time, error) {
+	return parseRelativeTime(value, base, false)
+}
+
+// parse time like parseListTime, time of day is the last occurrence before base if past is set
+func parseRelativeTime(value string, base time.Time, past bool) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if ret, err := time.Parse(time.RFC3339, value); err == nil {
+		return ret, nil
+	}
+
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02T15:04", "2006-01-02"} {
+		if ret, err := time.ParseInLocation(layout, value, base.Location()); err == nil {
+			return ret, nil
+		}
+	}
+
+	for _, layout := range []string{"15:04:05", "15:04"} {
+		if clock, err := time.ParseInLocation(layout, value, base.Location()); err == nil {
+			ret := time.Date(base.Year(), base.Month(), base.Day(), clock.Hour(), clock.Minute(), clock.Second(), 0, base.Location())
+			if past && ret.After(base) {
+				ret = ret.AddDate(0, 0, -1)
+			} else if !past && !ret.After(base) {
+				ret = ret.AddDate(0, 0, 1)
+			}
+			return ret, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("cannot parse time \"%s\"", value)
+}
@@ -1,15 +1,56 @@
 package main
 
 import (
+	"strings"
+
 	log "github.com/sirupsen/logrus"
 )
 
 func LogCronjobToFields(cronjob CrontabEntry) log.Fields {
-	return log.Fields{
+	fields := log.Fields{
+		"jobId":   cronjob.Id(),
 		"spec":    cronjob.Spec,
 		"user":    cronjob.User,
 		"command": cronjob.Command,
 		"crontab": cronjob.CrontabPath,
 		"shell":   cronjob.Shell,
 	}
+
+	if cronjob.ResolvedSpec != "" {
+		fields["resolvedSpec"] = cronjob.ResolvedSpec
+	}
+
+	if cronjob.Name != "" {
+		fields["name"] = cronjob.Name
+	}
+
+	if len(cronjob.Tags) >= 1 {
+		fields["tags"] = strings.Join(cronjob.Tags, ",")
+	}
+
+	if cronjob.Jitter != nil {
+		fields["jitter"] = cronjob.Jitter.String()
+	}
+
+	if cronjob.DryRun {
+		fields["dryRun"] = true
+	}
+
+	if cronjob.WorkDir != "" {
+		fields["workDir"] = cronjob.WorkDir
+	}
+
+	if cronjob.Timeout > 0 {
+		fields["timeout"] = cronjob.Timeout.String()
+	}
+
+	if cronjob.Retries > 0 {
+		fields["maxRetries"] = cronjob.Retries
+	}
+
+	if cronjob.ConcurrencyPolicy != "" {
+		fields["concurrencyPolicy"] = cronjob.ConcurrencyPolicy
+	}
+
+	return fields
 }
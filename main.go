@@ -10,7 +10,9 @@ import (
 	"path/filepath"
 	"runtime"
 	"strings"
+	"sync/atomic"
 	"syscall"
+	"time"
 
 	"github.com/webdevops/go-crond/config"
 
@@ -28,6 +30,19 @@ const (
 var (
 	opts      config.Opts
 	argparser *flags.Parser
+	history   *History
+
+	// currently active cron runner
+	currentRunner atomic.Pointer[Runner]
+
+	// reload requests of daemon (file changes, remote crontabs and api)
+	reloadRequests = make(chan string, 1)
+
+	// pidfile written by daemon (removed on shutdown)
+	daemonPidFile string
+
+	// directory of configuration (relative paths are resolved from it, current directory at start)
+	confDir string
 
 	// Git version information
 	gitCommit = "<unknown>"
@@ -36,7 +51,26 @@ var (
 
 func initArgParser() {
 	argparser = flags.NewParser(&opts, flags.Default)
-	_, err := argparser.Parse()
+	argparser.Usage = "[OPTIONS] [Crontabs...]"
+	argparser.SubcommandsOptional = true
+
+	// configuration file (used as defaults, overridden by env vars and arguments)
+	var configCrontabs []string
+	if configFile := config.ConfigFilePath(os.Args[1:]); configFile != "" {
+		var err error
+		if configCrontabs, err = config.LoadConfigFile(argparser, configFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+
+	// busybox style: called as crontab
+	cmdArgs := os.Args[1:]
+	if filepath.Base(os.Args[0]) == CRONTAB_COMMAND {
+		cmdArgs = append([]string{CRONTAB_COMMAND}, cmdArgs...)
+	}
+
+	args, err := argparser.ParseArgs(cmdArgs)
 
 	// check if there is an parse error
 	if err != nil {
@@ -50,6 +84,16 @@ func initArgParser() {
 		}
 	}
 
+	// remaining arguments are crontab files
+	opts.Args.Crontabs = args
+
+	// crontab files from configuration file (if not passed as arguments)
+	for _, crontabs := range []*[]string{&opts.Args.Crontabs, &opts.Run.Args.Crontabs, &opts.List.Args.Crontabs, &opts.Simulate.Args.Crontabs} {
+		if len(*crontabs) == 0 {
+			*crontabs = configCrontabs
+		}
+	}
+
 	// --dumpversion
 	if opts.ShowOnlyVersion {
 		fmt.Println(gitTag)
@@ -84,72 +128,132 @@ func initArgParser() {
 	}
 }
 
-func findFilesInPaths(pathlist []string, callback func(os.FileInfo, string)) {
+func findFilesInPaths(pathlist []string, callback func(os.FileInfo, string) error) error {
 	for _, path := range pathlist {
+		path, err := absConfPath(path)
+		if err != nil {
+			return err
+		}
+
 		if stat, err := os.Stat(path); err == nil && stat.IsDir() {
-			err := filepath.Walk(path, func(path string, f os.FileInfo, err error) error {
-				path, _ = filepath.Abs(path)
+			if err := walkDirectory(path, true, map[string]bool{}, callback); err != nil {
+				return err
+			}
+		} else {
+			log.Infof("path %s does not exists\n", path)
+		}
+	}
 
-				if f.IsDir() {
-					return nil
-				}
+	return nil
+}
 
-				if checkIfFileIsValid(f, path) {
-					callback(f, path)
-				}
+// Walk directory (recursively if set) and follow symlinks (files are checked by their resolved target).
+// Entries starting with ".." (Kubernetes ConfigMap/Secret data directories) are skipped,
+// visited contains resolved directories to prevent symlink loops.
+func walkDirectory(path string, recursive bool, visited map[string]bool, callback func(os.FileInfo, string) error) error {
+	resolvedPath, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		log.Infof("ignoring unresolvable directory %s: %v\n", path, err)
+		return nil
+	}
 
-				return nil
-			})
-			if err != nil {
-				log.Fatal(err)
+	if visited[resolvedPath] {
+		log.Infof("ignoring directory %s, already included (symlink loop or duplicate)\n", path)
+		return nil
+	}
+	visited[resolvedPath] = true
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		entryPath := filepath.Join(path, entry.Name())
+
+		if strings.HasPrefix(entry.Name(), "..") {
+			log.Debugf("ignoring hidden data directory %s", entryPath)
+			continue
+		}
+
+		// follows symlinks
+		f, err := os.Stat(entryPath)
+		if err != nil {
+			log.Infof("ignoring broken symlink %s\n", entryPath)
+			continue
+		}
+
+		if f.IsDir() {
+			if !recursive {
+				log.Debugf("ignoring subdirectory %s", entryPath)
+				continue
+			}
+			if err := walkDirectory(entryPath, recursive, visited, callback); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if err := filePolicy.CheckName(entryPath); err != nil {
+			log.Infof("ignoring file %s: %v\n", entryPath, err)
+			continue
+		}
+
+		if checkIfFileIsValid(f, entryPath) {
+			if err := callback(f, entryPath); err != nil {
+				return err
 			}
-		} else {
-			log.Infof("path %s does not exists\n", path)
 		}
 	}
+
+	return nil
+}
+
+func findExecutabesInPathes(pathlist []string, callback func(os.FileInfo, string) error) error {
+	return findFilesInPaths(pathlist, executableFiles(callback))
 }
 
-func findExecutabesInPathes(pathlist []string, callback func(os.FileInfo, string)) {
-	findFilesInPaths(pathlist, func(f os.FileInfo, path string) {
+// Callback only called for executable files
+func executableFiles(callback func(os.FileInfo, string) error) func(os.FileInfo, string) error {
+	return func(f os.FileInfo, path string) error {
 		if f.Mode().IsRegular() && (f.Mode().Perm()&0100 != 0) {
-			callback(f, path)
+			return callback(f, path)
 		} else {
 			log.Infof("ignoring non exectuable file %s\n", path)
 		}
-	})
+		return nil
+	}
 }
 
-func includePathsForCrontabs(paths []string, username string) []CrontabEntry {
+func includePathsForCrontabs(paths []string, username string) ([]CrontabEntry, error) {
 	var ret []CrontabEntry
-	findFilesInPaths(paths, func(f os.FileInfo, path string) {
-		entries := parseCrontab(path, username)
+	err := findFilesInPaths(paths, func(f os.FileInfo, path string) error {
+		entries, err := parseCrontab(path, username)
 		ret = append(ret, entries...)
+		return err
 	})
-	return ret
+	return ret, err
 }
 
-func includePathForCrontabs(path string, username string) []CrontabEntry {
-	var ret []CrontabEntry
-	var paths []string = []string{path}
-
-	findFilesInPaths(paths, func(f os.FileInfo, path string) {
-		entries := parseCrontab(path, username)
-		ret = append(ret, entries...)
-	})
-	return ret
+func includePathForCrontabs(path string, username string) ([]CrontabEntry, error) {
+	return includePathsForCrontabs([]string{path}, username)
 }
 
-func includeRunPartsDirectories(spec string, paths []string) []CrontabEntry {
+func includeRunPartsDirectories(spec string, paths []string) ([]CrontabEntry, error) {
 	var ret []CrontabEntry
 
 	for _, path := range paths {
-		ret = append(ret, includeRunPartsDirectory(spec, path)...)
+		entries, err := includeRunPartsDirectory(spec, path)
+		if err != nil {
+			return ret, err
+		}
+		ret = append(ret, entries...)
 	}
 
-	return ret
+	return ret, nil
 }
 
-func includeRunPartsDirectory(spec string, path string) []CrontabEntry {
+func includeRunPartsDirectory(spec string, path string) ([]CrontabEntry, error) {
 	var ret []CrontabEntry
 
 	user := opts.Cron.DefaultUser
@@ -160,14 +264,55 @@ func includeRunPartsDirectory(spec string, path string) []CrontabEntry {
 		user, path = split[0], split[1]
 	}
 
+	// directory as one cronjob, scripts are listed on every execution
+	if opts.Cron.RunPartsSequential {
+		absPath, err := absConfPath(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid run-parts directory %s: %w", path, err)
+		}
+
+		if !checkIfDirectoryExists(absPath) {
+			log.Infof("path %s does not exists\n", path)
+			return ret, nil
+		}
+
+		ret = append(ret, CrontabEntry{
+			Spec:    spec,
+			User:    user,
+			Command: fmt.Sprintf("run-parts %s", absPath),
+			RunParts: &RunParts{
+				Directory:   absPath,
+				ExitOnError: opts.Cron.RunPartsExitOnError,
+				Report:      opts.Cron.RunPartsReport,
+			},
+		})
+		return ret, nil
+	}
+
 	var paths []string = []string{path}
-	findExecutabesInPathes(paths, func(f os.FileInfo, path string) {
+	err := findExecutabesInPathes(paths, func(f os.FileInfo, path string) error {
 		ret = append(ret, CrontabEntry{Spec: spec, User: user, Command: path})
+		return nil
 	})
-	return ret
+	return ret, err
+}
+
+func parseCrontab(path string, username string) ([]CrontabEntry, error) {
+	// structured job definitions
+	if isJobFile(path) {
+		return parseJobFile(path, username)
+	}
+
+	parser, err := newCrontabParser(path, username)
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.Parse()
 }
 
-func parseCrontab(path string, username string) []CrontabEntry {
+// Create parser for system crontab or user crontab
+func newCrontabParser(path string, username string) (*Parser, error) {
 	var parser *Parser
 	var err error
 
@@ -178,41 +323,80 @@ func parseCrontab(path string, username string) []CrontabEntry {
 	}
 
 	if err != nil {
-		log.Fatalf("parser read err: %v", err)
+		return nil, fmt.Errorf("parser read err: %w", err)
 	}
 
-	crontabEntries := parser.Parse()
-
-	return crontabEntries
+	return parser, nil
 }
 
-func collectCrontabs(args []string) []CrontabEntry {
+func collectCrontabs(args []string) ([]CrontabEntry, error) {
 	var ret []CrontabEntry
 
 	// include system default crontab
 	if opts.Cron.Auto {
-		ret = append(ret, includeSystemDefaults()...)
+		entries, err := includeSystemDefaults()
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, entries...)
 	}
 
+	// crontabs from environment variables
+	entries, err := includeEnvironmentCrontabs()
+	if err != nil {
+		return nil, err
+	}
+	ret = append(ret, entries...)
+
 	// args: crontab files as normal arguments
 	for _, crontabPath := range args {
 		crontabUser := CRONTAB_TYPE_SYSTEM
 
-		if strings.Contains(crontabPath, ":") {
+		if !isRemoteCrontab(crontabPath) && strings.Contains(crontabPath, ":") {
 			split := strings.SplitN(crontabPath, ":", 2)
 			crontabUser, crontabPath = split[0], split[1]
 		}
 
-		crontabAbsPath, f := fileGetAbsolutePath(crontabPath)
+		// remote crontab (http/https)
+		if isRemoteCrontab(crontabPath) {
+			entries, err := includeRemoteCrontab(crontabPath, crontabUser)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, entries...)
+			continue
+		}
+
+		crontabAbsPath, f, err := fileGetAbsolutePath(crontabPath)
+		if err != nil {
+			return nil, err
+		}
+
 		if checkIfFileIsValid(f, crontabAbsPath) {
-			entries := parseCrontab(crontabAbsPath, crontabUser)
+			entries, err := parseCrontab(crontabAbsPath, crontabUser)
+			if err != nil {
+				return nil, err
+			}
 			ret = append(ret, entries...)
 		}
 	}
 
 	// --include-crond
 	if len(opts.Cron.IncludeCronD) >= 1 {
-		ret = append(ret, includePathsForCrontabs(opts.Cron.IncludeCronD, CRONTAB_TYPE_SYSTEM)...)
+		entries, err := includePathsForCrontabs(opts.Cron.IncludeCronD, CRONTAB_TYPE_SYSTEM)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, entries...)
+	}
+
+	// --spool-dir
+	if len(opts.Cron.SpoolDir) >= 1 {
+		entries, err := includeSpoolDirectories(opts.Cron.SpoolDir)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, entries...)
 	}
 
 	// --run-parts
@@ -223,51 +407,57 @@ func collectCrontabs(args []string) []CrontabEntry {
 				cronSpec, cronPath := split[0], split[1]
 				cronSpec = fmt.Sprintf("@every %s", cronSpec)
 
-				ret = append(ret, includeRunPartsDirectory(cronSpec, cronPath)...)
+				entries, err := includeRunPartsDirectory(cronSpec, cronPath)
+				if err != nil {
+					return nil, err
+				}
+				ret = append(ret, entries...)
 			} else {
 				log.Infof("ignoring --run-parts because of missing time spec: %s\n", runPart)
 			}
 		}
 	}
 
-	// --run-parts-1min
-	if len(opts.Cron.RunParts1m) >= 1 {
-		ret = append(ret, includeRunPartsDirectories("@every 1m", opts.Cron.RunParts1m)...)
-	}
-
-	// --run-parts-15min
-	if len(opts.Cron.RunParts15m) >= 1 {
-		ret = append(ret, includeRunPartsDirectories("*/15 * * * *", opts.Cron.RunParts15m)...)
-	}
-
-	// --run-parts-hourly
-	if len(opts.Cron.RunPartsHourly) >= 1 {
-		ret = append(ret, includeRunPartsDirectories("@hourly", opts.Cron.RunPartsHourly)...)
-	}
-
-	// --run-parts-daily
-	if len(opts.Cron.RunPartsDaily) >= 1 {
-		ret = append(ret, includeRunPartsDirectories("@daily", opts.Cron.RunPartsDaily)...)
-	}
-
-	// --run-parts-weekly
-	if len(opts.Cron.RunPartsWeekly) >= 1 {
-		ret = append(ret, includeRunPartsDirectories("@weekly", opts.Cron.RunPartsWeekly)...)
-	}
-
-	// --run-parts-monthly
-	if len(opts.Cron.RunPartsMonthly) >= 1 {
-		ret = append(ret, includeRunPartsDirectories("@monthly", opts.Cron.RunPartsMonthly)...)
+	runParts := []struct {
+		spec  string
+		paths []string
+	}{
+		// --run-parts-1min
+		{"@every 1m", opts.Cron.RunParts1m},
+		// --run-parts-15min
+		{"*/15 * * * *", opts.Cron.RunParts15m},
+		// --run-parts-hourly
+		{"@hourly", opts.Cron.RunPartsHourly},
+		// --run-parts-daily
+		{"@daily", opts.Cron.RunPartsDaily},
+		// --run-parts-weekly
+		{"@weekly", opts.Cron.RunPartsWeekly},
+		// --run-parts-monthly
+		{"@monthly", opts.Cron.RunPartsMonthly},
+	}
+	for _, runPart := range runParts {
+		if len(runPart.paths) >= 1 {
+			entries, err := includeRunPartsDirectories(runPart.spec, runPart.paths)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, entries...)
+		}
 	}
 
-	return ret
+	return ret, nil
 }
 
-func includeSystemDefaults() []CrontabEntry {
+func includeSystemDefaults() ([]CrontabEntry, error) {
 	var ret []CrontabEntry
 
 	systemDetected := false
 
+	include := func(entries []CrontabEntry, err error) error {
+		ret = append(ret, entries...)
+		return err
+	}
+
 	// ----------------------
 	// Alpine
 	// ----------------------
@@ -275,7 +465,9 @@ func includeSystemDefaults() []CrontabEntry {
 		log.Infof(" --> detected Alpine family, using distribution defaults")
 
 		if checkIfDirectoryExists("/etc/crontabs") {
-			ret = append(ret, includePathForCrontabs("/etc/crontabs", opts.Cron.DefaultUser)...)
+			if err := include(includePathForCrontabs("/etc/crontabs", opts.Cron.DefaultUser)); err != nil {
+				return nil, err
+			}
 		}
 
 		systemDetected = true
@@ -288,7 +480,9 @@ func includeSystemDefaults() []CrontabEntry {
 		log.Infof(" --> detected RedHat family, using distribution defaults")
 
 		if checkIfFileExistsAndOwnedByRoot("/etc/crontabs") {
-			ret = append(ret, includePathForCrontabs("/etc/crontabs", CRONTAB_TYPE_SYSTEM)...)
+			if err := include(includePathForCrontabs("/etc/crontabs", CRONTAB_TYPE_SYSTEM)); err != nil {
+				return nil, err
+			}
 		}
 
 		systemDetected = true
@@ -301,7 +495,9 @@ func includeSystemDefaults() []CrontabEntry {
 		log.Infof(" --> detected SuSE family, using distribution defaults")
 
 		if checkIfFileExistsAndOwnedByRoot("/etc/crontab") {
-			ret = append(ret, parseCrontab("/etc/crontab", CRONTAB_TYPE_SYSTEM)...)
+			if err := include(parseCrontab("/etc/crontab", CRONTAB_TYPE_SYSTEM)); err != nil {
+				return nil, err
+			}
 		}
 
 		systemDetected = true
@@ -314,7 +510,9 @@ func includeSystemDefaults() []CrontabEntry {
 		log.Infof(" --> detected Debian family, using distribution defaults")
 
 		if checkIfFileExistsAndOwnedByRoot("/etc/crontab") {
-			ret = append(ret, parseCrontab("/etc/crontab", CRONTAB_TYPE_SYSTEM)...)
+			if err := include(parseCrontab("/etc/crontab", CRONTAB_TYPE_SYSTEM)); err != nil {
+				return nil, err
+			}
 		}
 
 		systemDetected = true
@@ -325,44 +523,90 @@ func includeSystemDefaults() []CrontabEntry {
 	// ----------------------
 	if !systemDetected {
 		if checkIfFileExistsAndOwnedByRoot("/etc/crontab") {
-			ret = append(ret, includePathForCrontabs("/etc/crontab", CRONTAB_TYPE_SYSTEM)...)
+			if err := include(includePathForCrontabs("/etc/crontab", CRONTAB_TYPE_SYSTEM)); err != nil {
+				return nil, err
+			}
 		}
 
 		if checkIfFileExistsAndOwnedByRoot("/etc/crontabs") {
-			ret = append(ret, includePathForCrontabs("/etc/crontabs", CRONTAB_TYPE_SYSTEM)...)
+			if err := include(includePathForCrontabs("/etc/crontabs", CRONTAB_TYPE_SYSTEM)); err != nil {
+				return nil, err
+			}
 		}
 	}
 
 	if checkIfDirectoryExists("/etc/cron.d") {
-		ret = append(ret, includePathForCrontabs("/etc/cron.d", CRONTAB_TYPE_SYSTEM)...)
+		if err := include(includePathForCrontabs("/etc/cron.d", CRONTAB_TYPE_SYSTEM)); err != nil {
+			return nil, err
+		}
 	}
 
-	return ret
+	// user crontabs of spool directory (if not set by --spool-dir)
+	if len(opts.Cron.SpoolDir) == 0 {
+		if spoolDir := detectSpoolDir(); spoolDir != "" {
+			if err := include(includeSpoolDirectory(spoolDir)); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return ret, nil
+}
+
+// Create runner configured by options
+func newRunnerFromOpts() *Runner {
+	runner := NewRunner()
+	runner.history = history
+	runner.userSwitching = opts.Cron.EnableUserSwitching
+	runner.executor = ProcessExecutor{DryRun: opts.Cron.DryRun}
+	if opts.Cron.DryRun {
+		runner.notifier = nil
+	}
+	runner.jitter = opts.Cron.Jitter
+	runner.jitterDeterministic = opts.Cron.JitterDeterministic
+	runner.hashSalt = opts.Cron.HashSalt
+	runner.workDir = opts.Cron.WorkDir
+	return runner
 }
 
 func createCronRunner(args []string) *Runner {
-	crontabEntries := collectCrontabs(args)
+	crontabEntries, err := collectCrontabs(args)
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	runner := NewRunner()
+	runner := newRunnerFromOpts()
 
-	for _, crontabEntry := range crontabEntries {
-		if opts.Cron.EnableUserSwitching {
-			if err := runner.AddWithUser(crontabEntry); err != nil {
-				log.Fatal(err)
-			}
-		} else {
-			if err := runner.Add(crontabEntry); err != nil {
-				log.Fatal(err)
-			}
-		}
+	if _, err := runner.Update(crontabEntries); err != nil {
+		log.Fatal(err)
 	}
 
 	return runner
 }
 
+// Load and validate crontabs for commands (without adding them to runner)
+func loadCommandCrontabs(runner *Runner, args []string) []CrontabEntry {
+	crontabEntries, err := collectCrontabs(args)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if err := runner.Validate(crontabEntries); err != nil {
+		log.Fatal(err)
+	}
+
+	return crontabEntries
+}
+
 func main() {
 	initArgParser()
 
+	// crontab client (no daemon logs, also for unprivileged users)
+	if argparser.Active != nil && argparser.Active.Name == CRONTAB_COMMAND {
+		crontabCommand()
+		return
+	}
+
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, syscall.SIGHUP)
 
@@ -382,12 +626,68 @@ func main() {
 		}
 	}
 
+	// trust policy of crontab and run-parts files
+	policy, err := NewFilePolicy(opts)
+	if err != nil {
+		log.Fatal(err)
+	}
+	filePolicy = policy
+
 	// get current path
-	confDir, err := os.Getwd()
+	confDir, err = os.Getwd()
 	if err != nil {
 		log.Fatalf("could not get current path: %v", err)
 	}
 
+	// working directory of commands
+	if opts.Cron.WorkDir, err = absConfPath(opts.Cron.WorkDir); err != nil || !checkIfDirectoryExists(opts.Cron.WorkDir) {
+		log.Fatalf("invalid working directory %s", opts.Cron.WorkDir)
+	}
+
+	// remote crontabs (last good copies are cached in state directory)
+	remoteCacheDir := ""
+	if opts.Cron.StateDir != "" {
+		stateDir, err := absConfPath(opts.Cron.StateDir)
+		if err != nil {
+			log.Fatalf("invalid state directory %s: %v", opts.Cron.StateDir, err)
+		}
+		remoteCacheDir = filepath.Join(stateDir, REMOTE_CRONTAB_CACHE_DIR)
+	}
+	remoteCrontabs.Init(opts.Remote.Timeout, remoteCacheDir, opts.Remote.AllowHttp)
+
+	// commands
+	if argparser.Active != nil {
+		initMetrics()
+
+		switch argparser.Active.Name {
+		case "run":
+			runCommand()
+		case "list":
+			listCommand()
+		case "simulate":
+			simulateCommand()
+		}
+		return
+	}
+
+	// execution history (kept across reloads)
+	if history, err = openHistory(HISTORY_LOCK_TIMEOUT); err != nil {
+		log.Fatal(err)
+	}
+
+	// init mode (reaping of orphaned processes)
+	if opts.Cron.Init || os.Getpid() == 1 {
+		startReaper()
+	}
+
+	// run selected jobs once and exit
+	if len(opts.Cron.RunOnce) >= 1 {
+		initMetrics()
+		registerRunnerShutdown()
+		runOnceCommand()
+		return
+	}
+
 	// daemon mode
 	initMetrics()
 	if opts.Server.Bind != "" {
@@ -395,37 +695,124 @@ func main() {
 		startHttpServer()
 	}
 
-	// endless daemon-reload loop
-	for {
-		resetMetrics()
+	registerRunnerShutdown()
+	registerPauseSignals()
 
-		// change to initial directory for fetching crontabs
-		err = os.Chdir(confDir)
-		if err != nil {
-			log.Fatalf("cannot switch to path %s: %v", confDir, err)
+	// automatic reload on file changes
+	reload := reloadRequests
+	if opts.Watch.Enabled {
+		watcher := NewWatcher(collectWatchPaths(opts.Args.Crontabs), opts.Watch.Debounce, opts.Watch.Interval, reload)
+		watcher.Start(opts.Watch.Poll)
+	}
+
+	// automatic reload on remote crontab changes
+	remoteCrontabs.StartPolling(opts.Remote.Interval, reload)
+
+	if opts.Cron.DryRun {
+		log.Warn("dry-run mode enabled, commands are only logged and not executed")
+	}
+
+	if opts.Cron.PidFile != "" {
+		if err := os.WriteFile(opts.Cron.PidFile, []byte(fmt.Sprintf("%d\n", os.Getpid())), 0644); err != nil {
+			log.Fatalf("cannot write pidfile: %v", err)
 		}
+		daemonPidFile = opts.Cron.PidFile
+	}
 
-		// create new cron runner
-		runner := createCronRunner(opts.Args.Crontabs)
-		registerRunnerShutdown(runner)
+	// create cron runner (kept across reloads)
+	runner := newRunnerFromOpts()
+	currentRunner.Store(runner)
+
+	// endless daemon-reload loop
+	for initial := true; ; initial = false {
+		// update cron runner (only changed cronjobs are added or removed)
+		crontabEntries, err := collectCrontabs(opts.Args.Crontabs)
+		var result RunnerUpdateResult
+		if err == nil {
+			result, err = runner.Update(crontabEntries)
+		}
 
-		// chdir to root to prevent relative path errors
-		err = os.Chdir(opts.Cron.WorkDir)
 		if err != nil {
-			log.Fatalf("cannot switch to path %s: %v", confDir, err)
+			if initial {
+				log.Fatal(err)
+			}
+
+			// keep running with previous configuration
+			prometheusMetricConfigReloadSuccess.Set(0)
+			log.Errorf("reload failed, keeping previous configuration: %v", err)
+		} else {
+			prometheusMetricConfigReloadSuccess.Set(1)
+			prometheusMetricConfigReloadTime.SetToCurrentTime()
+			log.Infof("loaded configuration: %d added, %d removed, %d changed, %d unchanged cronjobs", result.Added, result.Removed, result.Changed, result.Unchanged)
 		}
 
-		// start new cron runner
+		// start cron runner (already running after reload)
 		runner.Start()
 
-		// check if we received SIGHUP and start a new loop
-		s := <-c
-		log.Infof("Got signal: %v", s)
-		runner.Stop()
+		// check if we received SIGHUP (or a reload request) and start a new loop
+		select {
+		case s := <-c:
+			log.Infof("Got signal: %v", s)
+		case reason := <-reload:
+			log.Infof("Got reload request: %v", reason)
+		}
 		log.Infof("Reloading configuration")
 	}
 }
 
+// Open execution history of state directory (nil if no state directory is set)
+func openHistory(lockTimeout time.Duration) (*History, error) {
+	if opts.Cron.StateDir == "" {
+		return nil, nil
+	}
+
+	stateDir, err := absConfPath(opts.Cron.StateDir)
+	if err != nil {
+		return nil, fmt.Errorf("invalid state directory %s: %w", opts.Cron.StateDir, err)
+	}
+
+	ret, err := NewHistory(stateDir, opts.History.RetentionCount, opts.History.RetentionAge, opts.History.OutputLimit, lockTimeout)
+	if err != nil {
+		return nil, err
+	}
+	log.Infof("recording execution history in %s", stateDir)
+	return ret, nil
+}
+
+// run job immediately and exit with its exit code
+func runCommand() {
+	// execution is recorded in history (not possible while database is locked by running daemon)
+	var err error
+	if history, err = openHistory(HISTORY_SHORT_LOCK_TIMEOUT); err != nil {
+		log.Warnf("execution is not recorded in history: %v", err)
+	}
+
+	runner := createCronRunner(opts.Run.Args.Crontabs)
+
+	run, err := runner.Trigger(opts.Run.Args.Job)
+	if err != nil {
+		log.Error(err)
+		os.Exit(1)
+	}
+
+	<-run.Done()
+	result := run.Result()
+	fmt.Print(result.Output)
+
+	if history != nil {
+		if err := history.Close(); err != nil {
+			log.Error(err)
+		}
+	}
+
+	if result.Result != JOB_RESULT_SUCCESS {
+		if result.ExitCode > 0 {
+			os.Exit(result.ExitCode)
+		}
+		os.Exit(1)
+	}
+}
+
 // start and handle prometheus handler
 func startHttpServer() {
 	go func() {
@@ -444,12 +831,23 @@ func startHttpServer() {
 				if _, err := fmt.Fprint(w, "Ok"); err != nil {
 					log.Error(err)
 				}
+
+				// details
+				for _, pause := range pauses.List() {
+					if _, err := fmt.Fprintf(w, "\npaused: %s", pause); err != nil {
+						log.Error(err)
+					}
+				}
 			})
 
 			if opts.Server.Metrics {
 				mux.Handle("/metrics", promhttp.Handler())
 			}
 
+			if opts.Server.Api {
+				registerApiHandlers(mux)
+			}
+
 			srv := &http.Server{
 				Addr:         opts.Server.Bind,
 				Handler:      mux,
@@ -461,13 +859,53 @@ func startHttpServer() {
 	}()
 }
 
-func registerRunnerShutdown(runner *Runner) {
+// SIGUSR1 pauses, SIGUSR2 resumes all jobs
+func registerPauseSignals() {
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		for s := range c {
+			log.Infof("got signal: %v", s)
+			switch s {
+			case syscall.SIGUSR1:
+				pauses.Pause(PAUSE_SCOPE_ALL, "", time.Time{})
+			case syscall.SIGUSR2:
+				pauses.Resume(PAUSE_SCOPE_ALL, "")
+			}
+		}
+	}()
+}
+
+func registerRunnerShutdown() {
 	c := make(chan os.Signal, 2)
 	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
 	go func() {
 		s := <-c
 		log.Infof("got signal: %v", s)
-		runner.Stop()
+
+		// forward signal to running jobs and wait for them
+		if count := processes.Signal(s); count > 0 {
+			log.Infof("forwarded signal %v to %d running jobs", s, count)
+		}
+
+		if runner := currentRunner.Load(); runner != nil {
+			if !runner.StopAndWait(opts.Cron.ShutdownTimeout) {
+				log.Warnf("jobs still running after %v, killing them", opts.Cron.ShutdownTimeout)
+				processes.Signal(syscall.SIGKILL)
+			}
+		}
+
+		if history != nil {
+			if err := history.Close(); err != nil {
+				log.Error(err)
+			}
+		}
+
+		if daemonPidFile != "" {
+			if err := os.Remove(daemonPidFile); err != nil && !os.IsNotExist(err) {
+				log.Error(err)
+			}
+		}
 
 		log.Infof("terminated")
 		os.Exit(0)
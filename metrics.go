@@ -3,13 +3,25 @@ package main
 import "github.com/prometheus/client_golang/prometheus"
 
 var (
-	prometheusMetricTask            *prometheus.GaugeVec
-	prometheusMetricTaskRunCount    *prometheus.CounterVec
-	prometheusMetricTaskRunResult   *prometheus.GaugeVec
-	prometheusMetricTaskRunTime     *prometheus.GaugeVec
-	prometheusMetricTaskRunPrevTs   *prometheus.GaugeVec
-	prometheusMetricTaskRunNextTs   *prometheus.GaugeVec
-	prometheusMetricTaskRunDuration *prometheus.GaugeVec
+	prometheusMetricTask                *prometheus.GaugeVec
+	prometheusMetricTaskRunCount        *prometheus.CounterVec
+	prometheusMetricTaskRunResult       *prometheus.GaugeVec
+	prometheusMetricTaskRunTime         *prometheus.GaugeVec
+	prometheusMetricTaskRunPrevTs       *prometheus.GaugeVec
+	prometheusMetricTaskRunNextTs       *prometheus.GaugeVec
+	prometheusMetricTaskRunDuration     *prometheus.GaugeVec
+	prometheusMetricTaskRunSkipped      *prometheus.CounterVec
+	prometheusMetricTaskPaused          *prometheus.GaugeVec
+	prometheusMetricTaskRunLateness     *prometheus.GaugeVec
+	prometheusMetricConfigReloadSuccess prometheus.Gauge
+	prometheusMetricConfigReloadTime    prometheus.Gauge
+
+	prometheusMetricRemoteCrontabFetchSuccess *prometheus.GaugeVec
+	prometheusMetricRemoteCrontabFetchTime    *prometheus.GaugeVec
+	prometheusMetricRemoteCrontabAge          *prometheus.GaugeVec
+
+	prometheusMetricTaskRunPartsScriptResult   *prometheus.GaugeVec
+	prometheusMetricTaskRunPartsScriptDuration *prometheus.GaugeVec
 )
 
 func initMetrics() {
@@ -27,7 +39,7 @@ func initMetrics() {
 			Name: "gocrond_task_run_count",
 			Help: "gocrond task run count",
 		},
-		[]string{"cronSpec", "cronUser", "cronCommand", "result"},
+		[]string{"cronSpec", "cronUser", "cronCommand", "result", "trigger"},
 	)
 	prometheus.MustRegister(prometheusMetricTaskRunCount)
 
@@ -75,14 +87,92 @@ func initMetrics() {
 		[]string{"cronSpec", "cronUser", "cronCommand"},
 	)
 	prometheus.MustRegister(prometheusMetricTaskRunPrevTs)
-}
 
-func resetMetrics() {
-	prometheusMetricTask.Reset()
-	prometheusMetricTaskRunCount.Reset()
-	prometheusMetricTaskRunResult.Reset()
-	prometheusMetricTaskRunTime.Reset()
-	prometheusMetricTaskRunDuration.Reset()
-	prometheusMetricTaskRunNextTs.Reset()
-	prometheusMetricTaskRunPrevTs.Reset()
+	prometheusMetricTaskRunSkipped = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "gocrond_task_run_skipped_count",
+			Help: "gocrond task skipped run count",
+		},
+		[]string{"cronSpec", "cronUser", "cronCommand", "reason"},
+	)
+	prometheus.MustRegister(prometheusMetricTaskRunSkipped)
+
+	prometheusMetricTaskPaused = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gocrond_task_paused",
+			Help: "gocrond task paused (1=paused)",
+		},
+		[]string{"cronSpec", "cronUser", "cronCommand"},
+	)
+	prometheus.MustRegister(prometheusMetricTaskPaused)
+
+	prometheusMetricTaskRunLateness = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gocrond_task_run_lateness",
+			Help: "gocrond task last run start delay after schedule (seconds, including jitter)",
+		},
+		[]string{"cronSpec", "cronUser", "cronCommand"},
+	)
+	prometheus.MustRegister(prometheusMetricTaskRunLateness)
+
+	prometheusMetricTaskRunPartsScriptResult = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gocrond_task_run_parts_script_result",
+			Help: "gocrond last run result of script of sequential run-parts task",
+		},
+		[]string{"cronSpec", "cronUser", "cronCommand", "script"},
+	)
+	prometheus.MustRegister(prometheusMetricTaskRunPartsScriptResult)
+
+	prometheusMetricTaskRunPartsScriptDuration = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gocrond_task_run_parts_script_duration",
+			Help: "gocrond last run duration of script of sequential run-parts task",
+		},
+		[]string{"cronSpec", "cronUser", "cronCommand", "script"},
+	)
+	prometheus.MustRegister(prometheusMetricTaskRunPartsScriptDuration)
+
+	prometheusMetricConfigReloadSuccess = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gocrond_config_reload_success",
+			Help: "gocrond last configuration reload successful (1=success)",
+		},
+	)
+	prometheus.MustRegister(prometheusMetricConfigReloadSuccess)
+
+	prometheusMetricConfigReloadTime = prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "gocrond_config_reload_time",
+			Help: "gocrond last successful configuration reload ts",
+		},
+	)
+	prometheus.MustRegister(prometheusMetricConfigReloadTime)
+
+	prometheusMetricRemoteCrontabFetchSuccess = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gocrond_remote_crontab_fetch_success",
+			Help: "gocrond last fetch of remote crontab successful (1=success)",
+		},
+		[]string{"url"},
+	)
+	prometheus.MustRegister(prometheusMetricRemoteCrontabFetchSuccess)
+
+	prometheusMetricRemoteCrontabFetchTime = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gocrond_remote_crontab_fetch_time",
+			Help: "gocrond last successful fetch of remote crontab ts",
+		},
+		[]string{"url"},
+	)
+	prometheus.MustRegister(prometheusMetricRemoteCrontabFetchTime)
+
+	prometheusMetricRemoteCrontabAge = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "gocrond_remote_crontab_age",
+			Help: "gocrond age of used copy of remote crontab (seconds since last successful fetch, updated on every fetch)",
+		},
+		[]string{"url"},
+	)
+	prometheus.MustRegister(prometheusMetricRemoteCrontabAge)
 }
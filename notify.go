@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	NOTIFY_ON_FAILURE = "failure"
+	NOTIFY_ON_SUCCESS = "success"
+	NOTIFY_ON_ALWAYS  = "always"
+
+	NOTIFY_TIMEOUT = 30 * time.Second
+)
+
+// Notification after execution of cronjob (webhook or command)
+type Notification struct {
+	// when to notify (failure, success or always; default failure)
+	On string `json:"on,omitempty" yaml:"on"`
+
+	// url for http POST request with json payload
+	Webhook string `json:"webhook,omitempty" yaml:"webhook"`
+
+	// shell command, run details are passed as GOCROND_* env vars
+	Command string `json:"command,omitempty" yaml:"command"`
+}
+
+var (
+	// notifications which are still being sent
+	pendingNotifications sync.WaitGroup
+)
+
+type NotificationPayload struct {
+	Job ApiJob       `json:"job"`
+	Run JobRunResult `json:"run"`
+}
+
+// Check if notification should be sent for result
+func (n Notification) Matches(result string) bool {
+	switch n.On {
+	case NOTIFY_ON_ALWAYS:
+		return true
+	case NOTIFY_ON_SUCCESS:
+		return result == JOB_RESULT_SUCCESS
+	default:
+		return result == JOB_RESULT_ERROR
+	}
+}
+
+// Send all matching notifications of cronjob (in background)
+func sendNotifications(cronjob *CrontabEntry, job ApiJob, result JobRunResult) {
+	for _, notification := range cronjob.Notifications {
+		if !notification.Matches(result.Result) {
+			continue
+		}
+
+		pendingNotifications.Add(1)
+		go func(notification Notification) {
+			defer pendingNotifications.Done()
+
+			logFields := LogCronjobToFields(*cronjob)
+			logFields["run"] = result.Id
+
+			var err error
+			if notification.Webhook != "" {
+				logFields["webhook"] = notification.Webhook
+				err = sendWebhookNotification(notification.Webhook, NotificationPayload{Job: job, Run: result})
+			} else {
+				logFields["notifyCommand"] = notification.Command
+				err = runNotificationCommand(notification.Command, cronjob, result)
+			}
+
+			if err != nil {
+				log.WithFields(logFields).Errorf("notification failed: %v", err)
+			} else {
+				log.WithFields(logFields).Debugf("notification sent")
+			}
+		}(notification)
+	}
+}
+
+func sendWebhookNotification(url string, payload NotificationPayload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	client := http.Client{Timeout: NOTIFY_TIMEOUT}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func runNotificationCommand(command string, cronjob *CrontabEntry, result JobRunResult) error {
+	execCmd := exec.Command(DEFAULT_SHELL, "-c", command)
+	execCmd.Dir = opts.Cron.WorkDir
+
+	// run as user of cronjob (like the cronjob itself), never with privileges of daemon
+	if os.Geteuid() == 0 {
+		credential, err := userCredential(cronjob.User)
+		if err != nil {
+			return fmt.Errorf("user lookup failed: %w", err)
+		}
+		execCmd.SysProcAttr = &syscall.SysProcAttr{Credential: credential}
+	}
+
+	execCmd.Env = append(os.Environ(),
+		"GOCROND_JOB_ID="+result.JobId,
+		"GOCROND_JOB_NAME="+cronjob.Name,
+		"GOCROND_JOB_COMMAND="+cronjob.Command,
+		"GOCROND_RUN_ID="+result.Id,
+		"GOCROND_TRIGGER="+result.Trigger,
+		"GOCROND_RESULT="+result.Result,
+		"GOCROND_EXIT_CODE="+strconv.Itoa(result.ExitCode),
+	)
+
+	var output bytes.Buffer
+	execCmd.Stdout = &output
+	execCmd.Stderr = &output
+	if err := processes.RunWithTimeout(execCmd, NOTIFY_TIMEOUT); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(output.Bytes()))
+	}
+	return nil
+}
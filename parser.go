@@ -2,10 +2,16 @@ package main
 
 import (
 	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"regexp"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
@@ -14,29 +20,50 @@ import (
 const (
 	ENV_LINE = `^(\S+)=(\S+)\s*$`
 
+	// annotation for following cronjob line, eg. "# go-crond: name=backup tags=db,nightly"
+	ANNOTATION_LINE = `^#\s*go-crond:\s*(.*)$`
+
 	//                     ----spec------------------------------------    --user--  -cmd-
-	CRONJOB_SYSTEM = `^\s*([^@\s]+\s+\S+\s+\S+\s+\S+\s+\S+|@every\s+\S+)\s+([^\s]+)\s+(.+)$`
+	CRONJOB_SYSTEM = `^\s*([^@\s]+\s+\S+\s+\S+\s+\S+\s+\S+|@every\s+\S+|@calendar\([^)]*\))\s+([^\s]+)\s+(.+)$`
 
 	//                  ----spec------------------------------------    -cmd-
-	CRONJOB_USER = `^\s*([^@\s]+\s+\S+\s+\S+\s+\S+\s+\S+|@every\s+\S+)\s+(.+)$`
+	CRONJOB_USER = `^\s*([^@\s]+\s+\S+\s+\S+\s+\S+\s+\S+|@every\s+\S+|@calendar\([^)]*\))\s+(.+)$`
 
 	DEFAULT_SHELL = "sh"
 )
 
 var (
-	envLineRegex       = regexp.MustCompile(ENV_LINE)
-	cronjobSystemRegex = regexp.MustCompile(CRONJOB_SYSTEM)
-	cronjobUserRegex   = regexp.MustCompile(CRONJOB_USER)
+	envLineRegex        = regexp.MustCompile(ENV_LINE)
+	annotationLineRegex = regexp.MustCompile(ANNOTATION_LINE)
+	cronjobSystemRegex  = regexp.MustCompile(CRONJOB_SYSTEM)
+	cronjobUserRegex    = regexp.MustCompile(CRONJOB_USER)
 )
 
 type CrontabEntry struct {
-	Spec        string
-	User        string
-	Command     string
-	Env         []string
-	Shell       string
-	CrontabPath string
-	EntryId     cron.EntryID
+	Spec         string
+	ResolvedSpec string
+	User         string
+	Command      string
+	Env          []string
+	Shell        string
+	CrontabPath  string
+	CrontabLine  int
+	EntryId      cron.EntryID
+	Name         string
+	Tags         []string
+	DryRun       bool
+	Jitter       *time.Duration
+	Annotations  map[string]string
+
+	// options of job definitions (job files)
+	WorkDir           string
+	Timeout           time.Duration
+	Retries           int
+	ConcurrencyPolicy string
+	Notifications     []Notification
+
+	// sequential execution of run-parts directory (instead of command)
+	RunParts *RunParts
 }
 
 type Parser struct {
@@ -71,37 +98,100 @@ func (e *CrontabEntry) SetEntryId(eid cron.EntryID) {
 	(*e).EntryId = eid
 }
 
+// Fingerprint of complete cronjob configuration (changes if any setting is changed, moved lines and resolved specs are ignored)
+func (e *CrontabEntry) Fingerprint() string {
+	cronjob := *e
+	cronjob.EntryId = 0
+	cronjob.CrontabLine = 0
+	cronjob.ResolvedSpec = ""
+
+	data, err := json.Marshal(cronjob)
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
+
+// Source of cronjob (crontab path with line if available)
+func (e *CrontabEntry) Source() string {
+	if e.CrontabLine >= 1 {
+		return fmt.Sprintf("%s:%d", e.CrontabPath, e.CrontabLine)
+	}
+	return e.CrontabPath
+}
+
+// Check if cronjob has tag
+func (e *CrontabEntry) HasTag(tag string) bool {
+	for _, val := range e.Tags {
+		if val == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// Stable identifier of cronjob (independent of reloads and restarts)
+func (e *CrontabEntry) Id() string {
+	hash := sha256.New()
+	for _, val := range []string{e.CrontabPath, e.User, e.Spec, e.Command} {
+		hash.Write([]byte(val))
+		hash.Write([]byte{0})
+	}
+	return hex.EncodeToString(hash.Sum(nil))[0:16]
+}
+
 // Parse crontab
-func (p *Parser) Parse() []CrontabEntry {
-	entries := p.parseLines()
+func (p *Parser) Parse() ([]CrontabEntry, error) {
+	reader, err := os.Open(p.path)
+	if err != nil {
+		return nil, fmt.Errorf("crontab path: %v err: %w", p.path, err)
+	}
+	defer reader.Close()
 
-	return entries
+	return p.parseLines(reader)
+}
+
+// Parse crontab content from reader (path of parser is used as crontab path of entries)
+func (p *Parser) ParseReader(reader io.Reader) ([]CrontabEntry, error) {
+	return p.parseLines(reader)
 }
 
 // Parse lines from crontab
-func (p *Parser) parseLines() []CrontabEntry {
+func (p *Parser) parseLines(reader io.Reader) ([]CrontabEntry, error) {
 	var (
 		entries        []CrontabEntry
 		crontabSpec    string
 		crontabUser    string
 		crontabCommand string
 		environment    []string
+		annotations    map[string]string
+		randomDelay    *time.Duration
 	)
 
-	reader, err := os.Open(p.path)
-	if err != nil {
-		log.Fatalf("crontab path: %v err:%v", p.path, err)
-	}
-	defer reader.Close()
-
 	shell := DEFAULT_SHELL
 
 	specCleanupRegexp := regexp.MustCompile(`\s+`)
 
+	lineNumber := 0
 	scanner := bufio.NewScanner(reader)
 	for scanner.Scan() {
+		lineNumber++
 		line := strings.TrimSpace(scanner.Text())
 
+		// annotation line (for next cronjob)
+		if annotationLineRegex.MatchString(line) {
+			m := annotationLineRegex.FindStringSubmatch(line)
+			if annotations == nil {
+				annotations = map[string]string{}
+			}
+			for key, value := range parseAnnotations(m[1]) {
+				annotations[key] = value
+			}
+			continue
+		}
+
 		// comment line
 		if strings.HasPrefix(line, "#") {
 			continue
@@ -116,6 +206,15 @@ func (p *Parser) parseLines() []CrontabEntry {
 			if envName == "SHELL" {
 				// custom shell for command
 				shell = envValue
+			} else if envName == "RANDOM_DELAY" {
+				// random start delay in minutes (cronie)
+				minutes, err := strconv.Atoi(envValue)
+				if err != nil || minutes < 0 {
+					log.Warnf("ignoring invalid RANDOM_DELAY=%s in %s:%d", envValue, p.path, lineNumber)
+					continue
+				}
+				delay := time.Duration(minutes) * time.Minute
+				randomDelay = &delay
 			} else {
 				// normal environment variable
 				environment = append(environment, fmt.Sprintf("%s=%s", envName, envValue))
@@ -139,19 +238,76 @@ func (p *Parser) parseLines() []CrontabEntry {
 			// shrink white spaces for better handling
 			crontabSpec = specCleanupRegexp.ReplaceAllString(crontabSpec, " ")
 
-			entries = append(
-				entries,
-				CrontabEntry{
-					Spec:        crontabSpec,
-					User:        crontabUser,
-					Command:     crontabCommand,
-					Env:         environment,
-					Shell:       shell,
-					CrontabPath: p.path,
-				},
-			)
+			entry := CrontabEntry{
+				Spec:        crontabSpec,
+				User:        crontabUser,
+				Command:     crontabCommand,
+				Env:         environment,
+				Shell:       shell,
+				CrontabPath: p.path,
+				CrontabLine: lineNumber,
+				Jitter:      randomDelay,
+			}
+			entry.SetAnnotations(annotations)
+			entries = append(entries, entry)
+
+			// annotations are only valid for one cronjob
+			annotations = nil
 		}
 	}
 
-	return entries
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("crontab path: %v err: %w", p.path, err)
+	}
+
+	return entries, nil
+}
+
+// Set annotations and apply known annotations (name, tags, dry-run, jitter)
+func (e *CrontabEntry) SetAnnotations(annotations map[string]string) {
+	e.Annotations = annotations
+
+	for key, value := range annotations {
+		switch key {
+		case "name":
+			e.Name = value
+		case "tags":
+			e.Tags = nil
+			for _, tag := range strings.Split(value, ",") {
+				if tag = strings.TrimSpace(tag); tag != "" {
+					e.Tags = append(e.Tags, tag)
+				}
+			}
+		case "dry-run":
+			dryRun, err := strconv.ParseBool(value)
+			if err != nil {
+				log.WithFields(LogCronjobToFields(*e)).Warnf("ignoring invalid annotation %s=%s", key, value)
+				continue
+			}
+			e.DryRun = dryRun
+		case "jitter":
+			jitter, err := time.ParseDuration(value)
+			if err != nil || jitter < 0 {
+				log.WithFields(LogCronjobToFields(*e)).Warnf("ignoring invalid annotation %s=%s", key, value)
+				continue
+			}
+			e.Jitter = &jitter
+		default:
+			log.WithFields(LogCronjobToFields(*e)).Warnf("ignoring unknown annotation %s", key)
+		}
+	}
+}
+
+// Parse annotations ("key=value" or "key" for flags, separated by whitespace)
+func parseAnnotations(line string) map[string]string {
+	ret := map[string]string{}
+	for _, field := range strings.Fields(line) {
+		if strings.Contains(field, "=") {
+			split := strings.SplitN(field, "=", 2)
+			ret[split[0]] = split[1]
+		} else {
+			ret[field] = "true"
+		}
+	}
+	return ret
 }
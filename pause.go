@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	PAUSE_SCOPE_ALL = "all"
+	PAUSE_SCOPE_JOB = "job"
+	PAUSE_SCOPE_TAG = "tag"
+)
+
+type Pause struct {
+	Scope  string     `json:"scope"`
+	Target string     `json:"target,omitempty"`
+	Since  time.Time  `json:"since"`
+	Until  *time.Time `json:"until,omitempty"`
+	timer  *time.Timer
+}
+
+type PauseRegistry struct {
+	lock   sync.Mutex
+	pauses map[string]*Pause
+}
+
+var (
+	pauses = &PauseRegistry{pauses: map[string]*Pause{}}
+)
+
+// Human readable pause description
+func (p Pause) String() string {
+	ret := p.Scope
+	if p.Target != "" {
+		ret = fmt.Sprintf("%s %s", p.Scope, p.Target)
+	}
+
+	if p.Until != nil {
+		ret = fmt.Sprintf("%s (until %s)", ret, p.Until.Format(time.RFC3339))
+	}
+	return ret
+}
+
+// Pause all jobs, a job (see pauseJobTarget) or a tag, zero until pauses until resumed manually
+func (reg *PauseRegistry) Pause(scope, target string, until time.Time) Pause {
+	key := pauseKey(scope, target)
+	pause := &Pause{
+		Scope:  scope,
+		Target: target,
+		Since:  time.Now(),
+	}
+
+	reg.lock.Lock()
+	if prev := reg.pauses[key]; prev != nil && prev.timer != nil {
+		prev.timer.Stop()
+	}
+
+	// automatic resume
+	if !until.IsZero() {
+		pause.Until = &until
+		pause.timer = time.AfterFunc(time.Until(until), func() {
+			if reg.remove(key, pause) {
+				log.WithField("scope", scope).WithField("target", target).Infof("resumed automatically")
+				updatePauseMetrics()
+			}
+		})
+	}
+
+	reg.pauses[key] = pause
+	reg.lock.Unlock()
+
+	log.WithField("scope", scope).WithField("target", target).Infof("paused %s", pause)
+	updatePauseMetrics()
+
+	return *pause
+}
+
+// Resume paused jobs, returns false if scope/target was not paused
+func (reg *PauseRegistry) Resume(scope, target string) bool {
+	reg.lock.Lock()
+	pause := reg.pauses[pauseKey(scope, target)]
+	reg.lock.Unlock()
+
+	if pause == nil || !reg.remove(pauseKey(scope, target), pause) {
+		return false
+	}
+
+	log.WithField("scope", scope).WithField("target", target).Infof("resumed")
+	updatePauseMetrics()
+	return true
+}
+
+// Find pause affecting cronjob (nil if not paused)
+func (reg *PauseRegistry) Find(cronjob *CrontabEntry) *Pause {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	if pause := reg.pauses[pauseKey(PAUSE_SCOPE_ALL, "")]; pause != nil {
+		return pause
+	}
+
+	if pause := reg.pauses[pauseKey(PAUSE_SCOPE_JOB, pauseJobTarget(cronjob))]; pause != nil {
+		return pause
+	}
+
+	for _, tag := range cronjob.Tags {
+		if pause := reg.pauses[pauseKey(PAUSE_SCOPE_TAG, tag)]; pause != nil {
+			return pause
+		}
+	}
+
+	return nil
+}
+
+// Job pauses without cronjob (eg. job without name changed by reload)
+func (reg *PauseRegistry) Orphaned(cronjobs []*CrontabEntry) []Pause {
+	targets := map[string]bool{}
+	for _, cronjob := range cronjobs {
+		targets[pauseJobTarget(cronjob)] = true
+	}
+
+	var ret []Pause
+	for _, pause := range reg.List() {
+		if pause.Scope == PAUSE_SCOPE_JOB && !targets[pause.Target] {
+			ret = append(ret, pause)
+		}
+	}
+	return ret
+}
+
+// List all active pauses
+func (reg *PauseRegistry) List() []Pause {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	ret := []Pause{}
+	for _, pause := range reg.pauses {
+		ret = append(ret, *pause)
+	}
+
+	sort.Slice(ret, func(i, j int) bool {
+		return pauseKey(ret[i].Scope, ret[i].Target) < pauseKey(ret[j].Scope, ret[j].Target)
+	})
+	return ret
+}
+
+// remove pause if it is still the active one for key
+func (reg *PauseRegistry) remove(key string, pause *Pause) bool {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	if reg.pauses[key] != pause {
+		return false
+	}
+
+	if pause.timer != nil {
+		pause.timer.Stop()
+	}
+	delete(reg.pauses, key)
+	return true
+}
+
+// Target of job pause: name of job (kept if job is changed) or job id if job has no name
+func pauseJobTarget(cronjob *CrontabEntry) string {
+	if cronjob.Name != "" {
+		return cronjob.Name
+	}
+	return cronjob.Id()
+}
+
+func pauseKey(scope, target string) string {
+	return scope + ":" + target
+}
+
+// update paused metrics of current runner
+func updatePauseMetrics() {
+	if runner := currentRunner.Load(); runner != nil {
+		runner.updatePauseMetrics()
+	}
+}
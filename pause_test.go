@@ -0,0 +1,45 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPauseJobAcrossUpdate(t *testing.T) {
+	runner, _, _ := newTestRunner(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Minute)
+
+	named := CrontabEntry{Spec: "@hourly", User: "root", Command: "/usr/local/bin/backup", CrontabPath: "/etc/crontab", Name: "backup"}
+	unnamed := CrontabEntry{Spec: "@daily", User: "root", Command: "/usr/local/bin/cleanup", CrontabPath: "/etc/crontab"}
+	if _, err := runner.Update([]CrontabEntry{named, unnamed}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cronjob := range runner.Jobs() {
+		pauses.Pause(PAUSE_SCOPE_JOB, pauseJobTarget(cronjob), time.Time{})
+	}
+	unnamedId := unnamed.Id()
+	defer pauses.Resume(PAUSE_SCOPE_JOB, "backup")
+	defer pauses.Resume(PAUSE_SCOPE_JOB, unnamedId)
+
+	// changed spec and command (new job id)
+	named.Spec, named.Command = "@daily", "/usr/local/bin/backup --full"
+	unnamed.Spec = "@weekly"
+	if _, err := runner.Update([]CrontabEntry{named, unnamed}); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, cronjob := range runner.Jobs() {
+		paused := pauses.Find(cronjob) != nil
+		if cronjob.Name == "backup" && !paused {
+			t.Errorf("named job not paused after update")
+		}
+		if cronjob.Name == "" && paused {
+			t.Errorf("unnamed job paused after update, expected new job id %s", cronjob.Id())
+		}
+	}
+
+	orphaned := pauses.Orphaned(runner.Jobs())
+	if len(orphaned) != 1 || orphaned[0].Target != unnamedId {
+		t.Errorf("got orphaned pauses %v, expected pause of job %s", orphaned, unnamedId)
+	}
+}
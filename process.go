@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+type ProcessRegistry struct {
+	lock      sync.Mutex
+	processes map[int]*exec.Cmd
+}
+
+var (
+	processes = &ProcessRegistry{processes: map[int]*exec.Cmd{}}
+)
+
+// Start command in own process group, wait for it and track it while running
+func (p *ProcessRegistry) Run(cmd *exec.Cmd) error {
+	return p.RunWithTimeout(cmd, 0)
+}
+
+// Run command, the process group is killed after timeout (no timeout if zero)
+func (p *ProcessRegistry) RunWithTimeout(cmd *exec.Cmd, timeout time.Duration) error {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+
+	// start and register process atomically, the zombie reaper must not reap it
+	p.lock.Lock()
+	if err := cmd.Start(); err != nil {
+		p.lock.Unlock()
+		return err
+	}
+	pid := cmd.Process.Pid
+	p.processes[pid] = cmd
+	p.lock.Unlock()
+
+	timedOut := false
+	if timeout > 0 {
+		timer := time.AfterFunc(timeout, func() {
+			p.lock.Lock()
+			timedOut = true
+			p.lock.Unlock()
+
+			if err := syscall.Kill(-pid, syscall.SIGKILL); err != nil {
+				log.Warnf("cannot kill process group %d after timeout: %v", pid, err)
+			}
+		})
+		defer timer.Stop()
+	}
+
+	err := cmd.Wait()
+
+	p.lock.Lock()
+	delete(p.processes, pid)
+	if timedOut {
+		err = fmt.Errorf("timeout after %v", timeout)
+	}
+	p.lock.Unlock()
+
+	return err
+}
+
+// Kill process group of running command (eg. replaced by newer execution)
+func (p *ProcessRegistry) Kill(cmd *exec.Cmd) error {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if cmd.Process == nil || !p.isTracked(cmd.Process.Pid) {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+// Check if pid is a tracked job process (must be called with lock held)
+func (p *ProcessRegistry) isTracked(pid int) bool {
+	_, exists := p.processes[pid]
+	return exists
+}
+
+// Forward signal to process groups of all running jobs, returns number of signaled jobs
+func (p *ProcessRegistry) Signal(sig os.Signal) int {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	count := 0
+	for pid := range p.processes {
+		sysSig, ok := sig.(syscall.Signal)
+		if !ok {
+			continue
+		}
+
+		// negative pid: send to whole process group
+		if err := syscall.Kill(-pid, sysSig); err != nil {
+			log.Warnf("cannot forward signal %v to process group %d: %v", sig, pid, err)
+			continue
+		}
+		count++
+	}
+
+	return count
+}
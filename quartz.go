@@ -0,0 +1,280 @@
+package main
+
+import (
+	"fmt"
+	"math/bits"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+)
+
+const (
+	// bit set by cron parser for "*" and "?" fields
+	cronStarBit = 1 << 63
+
+	// maximum search range for next activation
+	SCHEDULE_SEARCH_YEARS = 5
+)
+
+var (
+	quartzDomRegex = regexp.MustCompile(`^(?i)(L|LW|(\d{1,2})W)$`)
+	quartzDowRegex = regexp.MustCompile(`^(?i)(L|(\w+)L|(\w+)#([1-5]))$`)
+
+	quartzDowNames = map[string]int{"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6}
+)
+
+// Schedule parser with Quartz style day fields (L, LW, nW in day of month; L, dL, d#n in day of week),
+// other specs are parsed by the standard cron parser
+type QuartzParser struct {
+	parser cron.Parser
+}
+
+type QuartzSchedule struct {
+	spec *cron.SpecSchedule
+
+	// day of month: last day (L), last weekday (LW) or nearest weekday (nW)
+	domLast    bool
+	domWeekday bool
+	domDay     int
+
+	// day of week: weekday (0=sunday) in last week of month (dL) or nth weekday of month (d#n)
+	dowDay  int
+	dowLast bool
+	dowNth  int
+}
+
+func NewQuartzParser(options cron.ParseOption) QuartzParser {
+	return QuartzParser{parser: cron.NewParser(options)}
+}
+
+// Parse spec (Quartz day fields are only supported in specs with 5 fields)
+func (p QuartzParser) Parse(spec string) (cron.Schedule, error) {
+	fields := strings.Fields(spec)
+
+	// optional time zone prefix (CRON_TZ=... or TZ=...)
+	offset := 0
+	if len(fields) >= 1 && strings.Contains(fields[0], "=") {
+		offset = 1
+	}
+
+	if strings.HasPrefix(spec, "@") || len(fields)-offset != 5 {
+		return p.parser.Parse(spec)
+	}
+
+	domField, dowField := fields[offset+2], fields[offset+4]
+	domMatch := quartzDomRegex.FindStringSubmatch(domField)
+	dowMatch := quartzDowRegex.FindStringSubmatch(dowField)
+	if domMatch == nil && dowMatch == nil {
+		return p.parser.Parse(spec)
+	}
+
+	schedule := &QuartzSchedule{dowDay: -1}
+
+	// day of month
+	if domMatch != nil {
+		switch strings.ToUpper(domMatch[1]) {
+		case "L":
+			schedule.domLast = true
+		case "LW":
+			schedule.domLast = true
+			schedule.domWeekday = true
+		default:
+			schedule.domWeekday = true
+			schedule.domDay, _ = strconv.Atoi(domMatch[2])
+			if schedule.domDay < 1 || schedule.domDay > 31 {
+				return nil, fmt.Errorf("invalid day of month %s in spec \"%s\"", domField, spec)
+			}
+		}
+		fields[offset+2] = "*"
+	}
+
+	// day of week
+	if dowMatch != nil {
+		var err error
+		switch {
+		case strings.ToUpper(dowMatch[1]) == "L":
+			// last day of week (saturday)
+			schedule.dowDay = 6
+		case dowMatch[2] != "":
+			schedule.dowLast = true
+			schedule.dowDay, err = parseQuartzWeekday(dowMatch[2])
+		default:
+			schedule.dowDay, err = parseQuartzWeekday(dowMatch[3])
+			schedule.dowNth, _ = strconv.Atoi(dowMatch[4])
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid day of week %s in spec \"%s\": %w", dowField, spec, err)
+		}
+		fields[offset+4] = "*"
+	}
+
+	// all other fields are handled by the standard parser
+	parsed, err := p.parser.Parse(strings.Join(fields, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	specSchedule, ok := parsed.(*cron.SpecSchedule)
+	if !ok {
+		return nil, fmt.Errorf("unsupported spec \"%s\"", spec)
+	}
+	schedule.spec = specSchedule
+
+	return schedule, nil
+}
+
+// Next activation time after t
+func (s *QuartzSchedule) Next(t time.Time) time.Time {
+	origLocation := t.Location()
+	loc := s.spec.Location
+	if loc == time.Local {
+		loc = t.Location()
+	}
+	t = t.In(loc)
+
+	// start at next full second
+	t = t.Add(time.Second - time.Duration(t.Nanosecond())*time.Nanosecond)
+
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+	end := day.AddDate(SCHEDULE_SEARCH_YEARS, 0, 0)
+	for ; day.Before(end); day = day.AddDate(0, 0, 1) {
+		if s.spec.Month&(1<<uint(day.Month())) == 0 || !s.dayMatches(day) {
+			continue
+		}
+
+		if next := s.nextTimeOfDay(day, t); !next.IsZero() {
+			return next.In(origLocation)
+		}
+	}
+
+	return time.Time{}
+}
+
+// first activation at day which is not before t
+func (s *QuartzSchedule) nextTimeOfDay(day, t time.Time) time.Time {
+	return nextTimeOfDay(day, t, s.spec.Hour, s.spec.Minute, s.spec.Second)
+}
+
+// check day of month and day of week (like cron: either matches if both are restricted)
+func (s *QuartzSchedule) dayMatches(day time.Time) bool {
+	domSpecial := s.domLast || s.domWeekday
+	dowSpecial := s.dowDay >= 0
+
+	domRestricted := domSpecial || s.spec.Dom&cronStarBit == 0
+	dowRestricted := dowSpecial || s.spec.Dow&cronStarBit == 0
+
+	var domMatch, dowMatch bool
+	if domSpecial {
+		domMatch = s.domMatches(day)
+	} else {
+		domMatch = s.spec.Dom&(1<<uint(day.Day())) != 0
+	}
+	if dowSpecial {
+		dowMatch = s.dowMatches(day)
+	} else {
+		dowMatch = s.spec.Dow&(1<<uint(day.Weekday())) != 0
+	}
+
+	if domRestricted && dowRestricted {
+		return domMatch || dowMatch
+	}
+	return domMatch && dowMatch
+}
+
+func (s *QuartzSchedule) domMatches(day time.Time) bool {
+	lastDay := daysInMonth(day)
+
+	target := s.domDay
+	if s.domLast {
+		target = lastDay
+	}
+
+	if !s.domWeekday {
+		return day.Day() == target
+	}
+
+	// day does not exist in this month
+	if target > lastDay {
+		return false
+	}
+
+	// nearest weekday inside of the same month
+	targetDay := time.Date(day.Year(), day.Month(), target, 0, 0, 0, 0, day.Location())
+	switch targetDay.Weekday() {
+	case time.Saturday:
+		if target == 1 {
+			target += 2
+		} else {
+			target--
+		}
+	case time.Sunday:
+		if target == lastDay {
+			target -= 2
+		} else {
+			target++
+		}
+	}
+
+	return day.Day() == target
+}
+
+func (s *QuartzSchedule) dowMatches(day time.Time) bool {
+	if int(day.Weekday()) != s.dowDay {
+		return false
+	}
+
+	switch {
+	case s.dowLast:
+		return day.Day()+7 > daysInMonth(day)
+	case s.dowNth > 0:
+		return (day.Day()-1)/7+1 == s.dowNth
+	default:
+		return true
+	}
+}
+
+// first time at day matching hours, minutes and seconds (bitmasks) which is not before t
+func nextTimeOfDay(day, t time.Time, hours, minutes, seconds uint64) time.Time {
+	for hourBits := hours & (1<<24 - 1); hourBits != 0; hourBits &= hourBits - 1 {
+		hour := bits.TrailingZeros64(hourBits)
+		for minuteBits := minutes & (1<<60 - 1); minuteBits != 0; minuteBits &= minuteBits - 1 {
+			minute := bits.TrailingZeros64(minuteBits)
+			for secondBits := seconds & (1<<60 - 1); secondBits != 0; secondBits &= secondBits - 1 {
+				second := bits.TrailingZeros64(secondBits)
+
+				next := time.Date(day.Year(), day.Month(), day.Day(), hour, minute, second, 0, day.Location())
+
+				// skip times which do not exist (daylight saving time)
+				if next.Hour() != hour || next.Day() != day.Day() {
+					continue
+				}
+
+				if !next.Before(t) {
+					return next
+				}
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// number of days of month
+func daysInMonth(t time.Time) int {
+	return time.Date(t.Year(), t.Month()+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// parse weekday (0-7 or name)
+func parseQuartzWeekday(value string) (int, error) {
+	if day, exists := quartzDowNames[strings.ToLower(value)]; exists {
+		return day, nil
+	}
+
+	day, err := strconv.Atoi(value)
+	if err != nil || day < 0 || day > 7 {
+		return 0, fmt.Errorf("invalid weekday %s", value)
+	}
+	return day % 7, nil
+}
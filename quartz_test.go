@@ -0,0 +1,89 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	cron "github.com/robfig/cron/v3"
+)
+
+func TestQuartzScheduleNext(t *testing.T) {
+	parser := NewQuartzParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+	tests := []struct {
+		name string
+		spec string
+		from string
+		next string
+	}{
+		// last day of month
+		{"L february leap year", "0 0 L 2 *", "2024-01-15T00:00:00Z", "2024-02-29T00:00:00Z"},
+		{"L february non-leap year", "0 0 L 2 *", "2023-01-15T00:00:00Z", "2023-02-28T00:00:00Z"},
+
+		// last weekday of month
+		{"LW saturday at end of month", "0 12 LW * *", "2024-08-01T00:00:00Z", "2024-08-30T12:00:00Z"},
+		{"LW sunday at end of month", "0 12 LW * *", "2024-03-01T00:00:00Z", "2024-03-29T12:00:00Z"},
+		{"LW weekday at end of month", "0 12 LW * *", "2024-07-01T00:00:00Z", "2024-07-31T12:00:00Z"},
+
+		// nearest weekday
+		{"15W saturday", "0 0 15W * *", "2024-06-01T00:00:00Z", "2024-06-14T00:00:00Z"},
+		{"15W sunday", "0 0 15W * *", "2024-09-01T00:00:00Z", "2024-09-16T00:00:00Z"},
+		{"31W sunday at end of month", "0 0 31W * *", "2024-03-01T00:00:00Z", "2024-03-29T00:00:00Z"},
+		{"31W skips months without 31st", "0 0 31W * *", "2024-04-01T00:00:00Z", "2024-05-31T00:00:00Z"},
+		{"1W saturday", "0 0 1W * *", "2024-05-15T00:00:00Z", "2024-06-03T00:00:00Z"},
+		{"1W sunday", "0 0 1W * *", "2024-08-15T00:00:00Z", "2024-09-02T00:00:00Z"},
+
+		// day of week
+		{"5#5 month with four fridays", "0 0 * * 5#5", "2024-02-01T00:00:00Z", "2024-03-29T00:00:00Z"},
+		{"FRI#1", "0 0 * * FRI#1", "2024-02-03T00:00:00Z", "2024-03-01T00:00:00Z"},
+		{"5L last friday", "0 0 * * 5L", "2024-02-01T00:00:00Z", "2024-02-23T00:00:00Z"},
+
+		// year rollover
+		{"L december to january", "0 0 L * *", "2024-12-31T12:00:00Z", "2025-01-31T00:00:00Z"},
+		{"1W december to january", "0 0 1W * *", "2024-12-05T00:00:00Z", "2025-01-01T00:00:00Z"},
+
+		// time of day
+		{"same day later time", "30 9,17 L * *", "2024-01-31T10:00:00Z", "2024-01-31T17:30:00Z"},
+		{"exact time is not next", "30 9 L * *", "2024-01-31T09:30:00Z", "2024-02-29T09:30:00Z"},
+
+		// time zone and daylight saving time
+		{"CRON_TZ after end of dst", "CRON_TZ=Europe/Berlin 0 3 L * *", "2024-10-30T00:00:00Z", "2024-10-31T02:00:00Z"},
+		{"CRON_TZ during dst", "CRON_TZ=Europe/Berlin 0 3 L * *", "2024-09-01T00:00:00Z", "2024-09-30T01:00:00Z"},
+		{"CRON_TZ skipped by start of dst", "CRON_TZ=Europe/Berlin 30 2 L 3 *", "2024-03-01T00:00:00Z", "2025-03-31T00:30:00Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := parser.Parse(test.spec)
+			if err != nil {
+				t.Fatalf("parse of %q failed: %v", test.spec, err)
+			}
+			if _, ok := schedule.(*QuartzSchedule); !ok {
+				t.Fatalf("spec %q not parsed as quartz schedule", test.spec)
+			}
+
+			from, _ := time.Parse(time.RFC3339, test.from)
+			expected, _ := time.Parse(time.RFC3339, test.next)
+
+			if next := schedule.Next(from); !next.Equal(expected) {
+				t.Errorf("Next(%s) of %q = %s, expected %s", test.from, test.spec, next.UTC().Format(time.RFC3339), test.next)
+			}
+		})
+	}
+}
+
+func TestQuartzParserInvalid(t *testing.T) {
+	parser := NewQuartzParser(cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor)
+
+	for _, spec := range []string{
+		"0 0 32W * *",
+		"0 0 0W * *",
+		"0 0 * * 8#2",
+		"0 0 * * FOOL",
+		"0 0 * * 5#6",
+	} {
+		if _, err := parser.Parse(spec); err == nil {
+			t.Errorf("parse of %q should fail", spec)
+		}
+	}
+}
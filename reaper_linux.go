@@ -0,0 +1,108 @@
+package main
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	PR_SET_CHILD_SUBREAPER = 36
+
+	REAPER_INTERVAL = 30 * time.Second
+)
+
+// Start reaping of orphaned (zombie) child processes
+func startReaper() {
+	if os.Getpid() != 1 {
+		// become subreaper so orphaned grandchildren are reparented to go-crond
+		if _, _, errno := syscall.RawSyscall(syscall.SYS_PRCTL, PR_SET_CHILD_SUBREAPER, 1, 0); errno != 0 {
+			log.Warnf("cannot register as child subreaper: %v", errno)
+		}
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGCHLD)
+
+	go func() {
+		// also check periodically in case SIGCHLD signals were coalesced
+		ticker := time.NewTicker(REAPER_INTERVAL)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c:
+			case <-ticker.C:
+			}
+			reapZombies()
+		}
+	}()
+
+	log.Infof("init mode enabled, reaping orphaned processes")
+}
+
+// Reap all zombie children which are not owned by a running job (exec.Cmd.Wait reaps these)
+func reapZombies() {
+	// registry lock prevents starting new jobs while reaping, so a job process
+	// cannot be reaped here between fork and registration
+	processes.lock.Lock()
+	defer processes.lock.Unlock()
+
+	for _, pid := range findZombieChildren() {
+		if processes.isTracked(pid) {
+			continue
+		}
+
+		var status syscall.WaitStatus
+		if wpid, err := syscall.Wait4(pid, &status, syscall.WNOHANG, nil); err == nil && wpid == pid {
+			log.Debugf("reaped orphaned process %d (exit code %d)", pid, status.ExitStatus())
+		}
+	}
+}
+
+// Find all zombie processes with go-crond as parent
+func findZombieChildren() []int {
+	var ret []int
+
+	self := os.Getpid()
+
+	statFiles, err := filepath.Glob("/proc/[0-9]*/stat")
+	if err != nil {
+		return ret
+	}
+
+	for _, statFile := range statFiles {
+		content, err := os.ReadFile(statFile)
+		if err != nil {
+			continue
+		}
+
+		// format: pid (comm) state ppid ...; comm can contain spaces and brackets
+		stat := string(content)
+		commEnd := strings.LastIndex(stat, ")")
+		if commEnd == -1 {
+			continue
+		}
+
+		fields := strings.Fields(stat[commEnd+1:])
+		if len(fields) < 2 || fields[0] != "Z" {
+			continue
+		}
+
+		if ppid, err := strconv.Atoi(fields[1]); err != nil || ppid != self {
+			continue
+		}
+
+		if pid, err := strconv.Atoi(filepath.Base(filepath.Dir(statFile))); err == nil {
+			ret = append(ret, pid)
+		}
+	}
+
+	return ret
+}
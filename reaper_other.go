@@ -0,0 +1,12 @@
+//go:build !linux
+
+package main
+
+import (
+	log "github.com/sirupsen/logrus"
+)
+
+// Start reaping of orphaned (zombie) child processes
+func startReaper() {
+	log.Warnf("init mode (zombie reaping) is only supported on linux")
+}
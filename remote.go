@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// subdirectory of state directory for cached remote crontabs
+	REMOTE_CRONTAB_CACHE_DIR = "remote"
+
+	// maximum size of remote crontab
+	REMOTE_CRONTAB_MAX_SIZE = 1024 * 1024
+)
+
+// Remote crontab (fetched by http), the last successfully fetched content is kept and cached
+type RemoteCrontab struct {
+	Url          string    `json:"url"`
+	Content      []byte    `json:"content"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"lastModified,omitempty"`
+	FetchTime    time.Time `json:"fetchTime"`
+
+	lock sync.Mutex
+}
+
+type RemoteCrontabRegistry struct {
+	lock      sync.Mutex
+	crontabs  map[string]*RemoteCrontab
+	client    *http.Client
+	cacheDir  string
+	allowHttp bool
+}
+
+var (
+	remoteCrontabs = &RemoteCrontabRegistry{crontabs: map[string]*RemoteCrontab{}}
+)
+
+// Check if crontab path is a remote crontab (http or https url)
+func isRemoteCrontab(path string) bool {
+	return strings.HasPrefix(path, "https://") || strings.HasPrefix(path, "http://")
+}
+
+// Configure http client and cache directory (cache is disabled if empty), http urls are only allowed if allowHttp is set
+func (reg *RemoteCrontabRegistry) Init(timeout time.Duration, cacheDir string, allowHttp bool) {
+	reg.lock.Lock()
+	defer reg.lock.Unlock()
+
+	reg.client = &http.Client{Timeout: timeout}
+	reg.cacheDir = cacheDir
+	reg.allowHttp = allowHttp
+}
+
+// Get content of remote crontab, fetched on first usage (falls back to cached content if fetch fails)
+func (reg *RemoteCrontabRegistry) Content(crontabUrl string) ([]byte, error) {
+	reg.lock.Lock()
+	if strings.HasPrefix(crontabUrl, "http://") && !reg.allowHttp {
+		reg.lock.Unlock()
+		return nil, fmt.Errorf("insecure remote crontab %s (http is only allowed with --remote.allow-http)", crontabUrl)
+	}
+
+	crontab, exists := reg.crontabs[crontabUrl]
+	if !exists {
+		crontab = &RemoteCrontab{Url: crontabUrl}
+		reg.loadCache(crontab)
+		reg.crontabs[crontabUrl] = crontab
+	}
+	reg.lock.Unlock()
+
+	if !exists {
+		if _, err := reg.fetch(crontab); err != nil {
+			crontab.lock.Lock()
+			defer crontab.lock.Unlock()
+
+			if crontab.FetchTime.IsZero() {
+				return nil, fmt.Errorf("cannot fetch remote crontab %s: %w", crontabUrl, err)
+			}
+
+			log.WithField("crontab", crontabUrl).Warnf("cannot fetch remote crontab, using cached copy from %s: %v", crontab.FetchTime.Format(time.RFC3339), err)
+			return crontab.Content, nil
+		}
+	}
+
+	crontab.lock.Lock()
+	defer crontab.lock.Unlock()
+	return crontab.Content, nil
+}
+
+// Poll all remote crontabs in interval, reload is triggered if content has changed
+func (reg *RemoteCrontabRegistry) StartPolling(interval time.Duration, reload chan<- string) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for range ticker.C {
+			reg.lock.Lock()
+			var crontabs []*RemoteCrontab
+			for _, crontab := range reg.crontabs {
+				crontabs = append(crontabs, crontab)
+			}
+			reg.lock.Unlock()
+
+			changed := false
+			for _, crontab := range crontabs {
+				crontabChanged, err := reg.fetch(crontab)
+				if err != nil {
+					log.WithField("crontab", crontab.Url).Errorf("cannot fetch remote crontab, keeping last copy: %v", err)
+					continue
+				}
+				changed = changed || crontabChanged
+			}
+
+			if changed {
+				// non blocking, a pending reload also covers these changes
+				select {
+				case reload <- "remote crontab change":
+				default:
+				}
+			}
+		}
+	}()
+}
+
+// Fetch remote crontab (conditional request with ETag and Last-Modified), returns true if content has changed
+func (reg *RemoteCrontabRegistry) fetch(crontab *RemoteCrontab) (bool, error) {
+	changed, err := reg.request(crontab)
+
+	labels := prometheus.Labels{"url": crontab.Url}
+	if err != nil {
+		prometheusMetricRemoteCrontabFetchSuccess.With(labels).Set(0)
+	} else {
+		prometheusMetricRemoteCrontabFetchSuccess.With(labels).Set(1)
+	}
+
+	crontab.lock.Lock()
+	if !crontab.FetchTime.IsZero() {
+		prometheusMetricRemoteCrontabFetchTime.With(labels).Set(float64(crontab.FetchTime.Unix()))
+		prometheusMetricRemoteCrontabAge.With(labels).Set(time.Since(crontab.FetchTime).Seconds())
+	}
+	crontab.lock.Unlock()
+
+	if changed {
+		log.WithField("crontab", crontab.Url).Infof("fetched changed remote crontab")
+		reg.saveCache(crontab)
+	}
+
+	return changed, err
+}
+
+func (reg *RemoteCrontabRegistry) request(crontab *RemoteCrontab) (bool, error) {
+	req, err := http.NewRequest(http.MethodGet, crontab.Url, nil)
+	if err != nil {
+		return false, err
+	}
+
+	crontab.lock.Lock()
+	if crontab.ETag != "" {
+		req.Header.Set("If-None-Match", crontab.ETag)
+	}
+	if crontab.LastModified != "" {
+		req.Header.Set("If-Modified-Since", crontab.LastModified)
+	}
+	crontab.lock.Unlock()
+
+	resp, err := reg.client.Do(req)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNotModified:
+		crontab.lock.Lock()
+		crontab.FetchTime = time.Now()
+		crontab.lock.Unlock()
+		return false, nil
+	case http.StatusOK:
+	default:
+		return false, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	// incomplete crontabs are not used
+	content, err := io.ReadAll(io.LimitReader(resp.Body, REMOTE_CRONTAB_MAX_SIZE+1))
+	if err != nil {
+		return false, err
+	}
+	if len(content) > REMOTE_CRONTAB_MAX_SIZE {
+		return false, fmt.Errorf("remote crontab exceeds maximum size of %d bytes", REMOTE_CRONTAB_MAX_SIZE)
+	}
+
+	crontab.lock.Lock()
+	defer crontab.lock.Unlock()
+
+	changed := crontab.FetchTime.IsZero() || !bytes.Equal(content, crontab.Content)
+	crontab.Content = content
+	crontab.ETag = resp.Header.Get("ETag")
+	crontab.LastModified = resp.Header.Get("Last-Modified")
+	crontab.FetchTime = time.Now()
+
+	return changed, nil
+}
+
+// path of cache file of remote crontab (empty if cache is disabled)
+func (reg *RemoteCrontabRegistry) cachePath(crontab *RemoteCrontab) string {
+	if reg.cacheDir == "" {
+		return ""
+	}
+
+	hash := sha256.Sum256([]byte(crontab.Url))
+	return filepath.Join(reg.cacheDir, hex.EncodeToString(hash[:])[0:16]+".json")
+}
+
+// load last good copy of remote crontab from cache (must be called before fetching)
+func (reg *RemoteCrontabRegistry) loadCache(crontab *RemoteCrontab) {
+	path := reg.cachePath(crontab)
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.WithField("crontab", crontab.Url).Warnf("cannot read cache of remote crontab: %v", err)
+		}
+		return
+	}
+
+	var cached RemoteCrontab
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Url != crontab.Url {
+		log.WithField("crontab", crontab.Url).Warnf("ignoring invalid cache of remote crontab %s", path)
+		return
+	}
+
+	crontab.Content = cached.Content
+	crontab.ETag = cached.ETag
+	crontab.LastModified = cached.LastModified
+	crontab.FetchTime = cached.FetchTime
+}
+
+// save last good copy of remote crontab to cache
+func (reg *RemoteCrontabRegistry) saveCache(crontab *RemoteCrontab) {
+	path := reg.cachePath(crontab)
+	if path == "" {
+		return
+	}
+
+	crontab.lock.Lock()
+	data, err := json.Marshal(crontab)
+	crontab.lock.Unlock()
+	if err != nil {
+		log.WithField("crontab", crontab.Url).Errorf("cannot encode cache of remote crontab: %v", err)
+		return
+	}
+
+	// write to temporary file first, cache must not be corrupted
+	tmpPath := path + ".tmp"
+	err = os.MkdirAll(filepath.Dir(path), 0700)
+	if err == nil {
+		err = os.WriteFile(tmpPath, data, 0600)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		log.WithField("crontab", crontab.Url).Errorf("cannot write cache of remote crontab: %v", err)
+	}
+}
+
+// Parse remote crontab (crontab or job file, by extension of url path)
+func includeRemoteCrontab(crontabUrl string, username string) ([]CrontabEntry, error) {
+	content, err := remoteCrontabs.Content(crontabUrl)
+	if err != nil {
+		return nil, err
+	}
+
+	if parsedUrl, err := url.Parse(crontabUrl); err == nil && isJobFile(parsedUrl.Path) {
+		return parseJobFileReader(bytes.NewReader(content), crontabUrl, username)
+	}
+
+	parser, err := newCrontabParser(crontabUrl, username)
+	if err != nil {
+		return nil, err
+	}
+	return parser.ParseReader(bytes.NewReader(content))
+}
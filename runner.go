@@ -1,10 +1,16 @@
 package main
 
 import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
 	"os"
 	"os/exec"
 	"os/user"
+	"sort"
 	"strconv"
+	"sync"
 	"syscall"
 	"time"
 
@@ -13,39 +19,94 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	// concurrent executions of cronjob are allowed (default)
+	CONCURRENCY_POLICY_ALLOW = "allow"
+	// scheduled execution is skipped if cronjob is still running
+	CONCURRENCY_POLICY_FORBID = "forbid"
+	// running executions are killed before scheduled execution
+	CONCURRENCY_POLICY_REPLACE = "replace"
+)
+
 type Runner struct {
-	cron     *cron.Cron
-	cronjobs map[cron.EntryID]*CrontabEntry
+	cron          *cron.Cron
+	parser        cron.ScheduleParser
+	lock          sync.RWMutex
+	cronjobs      map[cron.EntryID]*CrontabEntry
+	cmdCallbacks  map[cron.EntryID]func(*exec.Cmd) bool
+	history       *History
+	userSwitching bool
+	clock         Clock
+	executor      Executor
+
+	// running executions by cronjob (for concurrency policies), false if replaced
+	running map[*CrontabEntry]map[*runningExecution]bool
+
+	// send notifications of finished runs (disabled if nil)
+	notifier func(cronjob *CrontabEntry, job ApiJob, result JobRunResult)
+
+	// default random start delay of scheduled runs
+	jitter              time.Duration
+	jitterDeterministic bool
+
+	// salt for hashed fields (H) in specs
+	hashSalt string
+
+	// working directory of commands (if not set by cronjob)
+	workDir string
+
+	// closed if runner is stopped (interrupts start delays)
+	stop     chan struct{}
+	stopOnce sync.Once
+
+	// manual runs in background (waited for on shutdown)
+	manualRuns sync.WaitGroup
+}
+
+type RunnerUpdateResult struct {
+	Added     int
+	Removed   int
+	Changed   int
+	Unchanged int
 }
 
 func NewRunner() *Runner {
+	parser := NewCalendarParser(NewQuartzParser(
+		cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
+	))
+
 	r := &Runner{
-		cron: cron.New(
-			cron.WithParser(
-				cron.NewParser(
-					cron.Minute | cron.Hour | cron.Dom | cron.Month | cron.Dow | cron.Descriptor,
-				),
-			),
-		),
-		cronjobs: map[cron.EntryID]*CrontabEntry{},
+		cron:         cron.New(cron.WithParser(parser)),
+		parser:       parser,
+		cronjobs:     map[cron.EntryID]*CrontabEntry{},
+		cmdCallbacks: map[cron.EntryID]func(*exec.Cmd) bool{},
+		clock:        realClock{},
+		executor:     ProcessExecutor{},
+		running:      map[*CrontabEntry]map[*runningExecution]bool{},
+		notifier:     sendNotifications,
+		stop:         make(chan struct{}),
 	}
 	return r
 }
 
 // Add crontab entry
 func (r *Runner) Add(cronjob CrontabEntry) error {
-	eid, err := r.cron.AddFunc(cronjob.Spec, r.cmdFunc(&cronjob, func(execCmd *exec.Cmd) bool {
+	cmdCallback := func(execCmd *exec.Cmd) bool {
 		// before exec callback
 		log.WithFields(LogCronjobToFields(cronjob)).Infof("executing")
 		return true
-	}))
+	}
+	eid, err := r.addFunc(&cronjob, cmdCallback)
 
 	if err != nil {
 		prometheusMetricTask.With(r.cronjobToPrometheusLabels(cronjob)).Set(0)
 		log.WithFields(LogCronjobToFields(cronjob)).Errorf("cronjob failed adding:%v", err)
 	} else {
 		cronjob.SetEntryId(eid)
+		r.lock.Lock()
 		r.cronjobs[eid] = &cronjob
+		r.cmdCallbacks[eid] = cmdCallback
+		r.lock.Unlock()
 		prometheusMetricTask.With(r.cronjobToPrometheusLabels(cronjob)).Set(1)
 		log.WithFields(LogCronjobToFields(cronjob)).Infof("cronjob added")
 	}
@@ -55,43 +116,30 @@ func (r *Runner) Add(cronjob CrontabEntry) error {
 
 // Add crontab entry with user
 func (r *Runner) AddWithUser(cronjob CrontabEntry) error {
-	eid, err := r.cron.AddFunc(cronjob.Spec, r.cmdFunc(&cronjob, func(execCmd *exec.Cmd) bool {
+	cmdCallback := func(execCmd *exec.Cmd) bool {
 		// before exec callback
 		log.WithFields(LogCronjobToFields(cronjob)).Debugf("executing")
 
-		// lookup username
-		u, err := user.Lookup(cronjob.User)
+		// add process credentials
+		credential, err := userCredential(cronjob.User)
 		if err != nil {
 			log.WithFields(LogCronjobToFields(cronjob)).Errorf("user lookup failed: %v", err)
 			return false
 		}
-
-		// convert userid to int
-		userId, err := strconv.ParseUint(u.Uid, 10, 32)
-		if err != nil {
-			log.WithFields(LogCronjobToFields(cronjob)).Errorf("Cannot convert user to id:%v", err)
-			return false
-		}
-
-		// convert groupid to int
-		groupId, err := strconv.ParseUint(u.Gid, 10, 32)
-		if err != nil {
-			log.WithFields(LogCronjobToFields(cronjob)).Errorf("Cannot convert group to id:%v", err)
-			return false
-		}
-
-		// add process credentials
-		execCmd.SysProcAttr = &syscall.SysProcAttr{}
-		execCmd.SysProcAttr.Credential = &syscall.Credential{Uid: uint32(userId), Gid: uint32(groupId)}
+		execCmd.SysProcAttr = &syscall.SysProcAttr{Credential: credential}
 		return true
-	}))
+	}
+	eid, err := r.addFunc(&cronjob, cmdCallback)
 
 	if err != nil {
 		prometheusMetricTask.With(r.cronjobToPrometheusLabels(cronjob)).Set(0)
 		log.WithFields(LogCronjobToFields(cronjob)).Errorf("cronjob failed adding: %v", err)
 	} else {
 		cronjob.SetEntryId(eid)
+		r.lock.Lock()
 		r.cronjobs[eid] = &cronjob
+		r.cmdCallbacks[eid] = cmdCallback
+		r.lock.Unlock()
 		prometheusMetricTask.With(r.cronjobToPrometheusLabels(cronjob)).Set(1)
 		log.WithFields(LogCronjobToFields(cronjob)).Infof("cronjob added")
 	}
@@ -99,6 +147,177 @@ func (r *Runner) AddWithUser(cronjob CrontabEntry) error {
 	return err
 }
 
+// Process credentials of user (uid and primary gid)
+func userCredential(username string) (*syscall.Credential, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return nil, err
+	}
+
+	userId, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert user to id: %w", err)
+	}
+
+	groupId, err := strconv.ParseUint(u.Gid, 10, 32)
+	if err != nil {
+		return nil, fmt.Errorf("cannot convert group to id: %w", err)
+	}
+
+	return &syscall.Credential{Uid: uint32(userId), Gid: uint32(groupId)}, nil
+}
+
+// Add cronjob to cron with resolved spec (hashed fields)
+func (r *Runner) addFunc(cronjob *CrontabEntry, cmdCallback func(*exec.Cmd) bool) (cron.EntryID, error) {
+	spec, err := r.resolveSpec(cronjob)
+	if err != nil {
+		return 0, err
+	}
+
+	if spec != cronjob.Spec {
+		cronjob.ResolvedSpec = spec
+	}
+
+	return r.cron.AddFunc(spec, r.cmdFunc(cronjob, cmdCallback))
+}
+
+// Resolve hashed fields (H) of cronjob spec
+func (r *Runner) resolveSpec(cronjob *CrontabEntry) (string, error) {
+	return resolveHashedSpec(cronjob.Spec, cronjob.Id(), r.hashSalt)
+}
+
+// Parse schedule of cronjob (with resolved hashed fields)
+func (r *Runner) Schedule(cronjob *CrontabEntry) (cron.Schedule, error) {
+	spec, err := r.resolveSpec(cronjob)
+	if err != nil {
+		return nil, err
+	}
+	return r.parser.Parse(spec)
+}
+
+// Validate cronjobs without adding them (all invalid schedules are reported)
+func (r *Runner) Validate(cronjobs []CrontabEntry) error {
+	var errs []error
+	for i := range cronjobs {
+		cronjob := &cronjobs[i]
+		if _, err := r.Schedule(cronjob); err != nil {
+			errs = append(errs, fmt.Errorf("invalid schedule \"%s\" in %s (command: %s): %w", cronjob.Spec, cronjob.Source(), cronjob.Command, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Update cronjobs incrementally: only changed cronjobs are added or removed,
+// unchanged cronjobs keep their schedule, running executions and metrics (changed cronjobs keep their metrics).
+// Invalid cronjobs are rejected before any cronjob is changed.
+func (r *Runner) Update(cronjobs []CrontabEntry) (RunnerUpdateResult, error) {
+	var ret RunnerUpdateResult
+
+	if err := r.Validate(cronjobs); err != nil {
+		return ret, err
+	}
+
+	// current cronjobs by identity
+	currentList := r.Jobs()
+	current := map[string]*CrontabEntry{}
+	for i, key := range cronjobIdentityKeys(currentList) {
+		current[key] = currentList[i]
+	}
+
+	// new cronjobs by identity
+	var updatedList []*CrontabEntry
+	for i := range cronjobs {
+		updatedList = append(updatedList, &cronjobs[i])
+	}
+	updated := map[string]bool{}
+	updatedKeys := cronjobIdentityKeys(updatedList)
+	for _, key := range updatedKeys {
+		updated[key] = true
+	}
+
+	// removed cronjobs (metrics are deleted after update if labels are not used anymore)
+	var removed []*CrontabEntry
+	for key, cronjob := range current {
+		if !updated[key] {
+			removed = append(removed, r.remove(cronjob.EntryId))
+			ret.Removed++
+		}
+	}
+
+	// added and changed cronjobs
+	var err error
+	for i, key := range updatedKeys {
+		cronjob := updatedList[i]
+
+		currentCronjob, exists := current[key]
+		if exists {
+			if currentCronjob.Fingerprint() == cronjob.Fingerprint() {
+				ret.Unchanged++
+				continue
+			}
+			removed = append(removed, r.remove(currentCronjob.EntryId))
+		}
+
+		if addErr := r.AddEntry(*cronjob); addErr != nil {
+			err = addErr
+			continue
+		}
+
+		if exists {
+			ret.Changed++
+		} else {
+			ret.Added++
+		}
+	}
+
+	for _, cronjob := range removed {
+		if cronjob != nil {
+			r.deleteCronjobMetrics(cronjob)
+		}
+	}
+	r.initAllCronEntryMetrics()
+
+	// pauses of jobs without name are lost if job is changed
+	for _, pause := range pauses.Orphaned(r.Jobs()) {
+		log.WithField("scope", pause.Scope).WithField("target", pause.Target).Warnf("paused job not found, pause is not applied (resume with /api/jobs/%s/resume)", pause.Target)
+	}
+
+	return ret, err
+}
+
+// Add crontab entry (with user if user switching is enabled)
+func (r *Runner) AddEntry(cronjob CrontabEntry) error {
+	if r.userSwitching {
+		return r.AddWithUser(cronjob)
+	}
+	return r.Add(cronjob)
+}
+
+// Remove crontab entry (running executions are not affected)
+func (r *Runner) Remove(eid cron.EntryID) {
+	if cronjob := r.remove(eid); cronjob != nil {
+		r.deleteCronjobMetrics(cronjob)
+	}
+}
+
+// remove crontab entry without its metrics, returns removed cronjob (nil if not found)
+func (r *Runner) remove(eid cron.EntryID) *CrontabEntry {
+	r.lock.Lock()
+	cronjob, exists := r.cronjobs[eid]
+	delete(r.cronjobs, eid)
+	delete(r.cmdCallbacks, eid)
+	r.lock.Unlock()
+
+	r.cron.Remove(eid)
+
+	if !exists {
+		return nil
+	}
+
+	log.WithFields(LogCronjobToFields(*cronjob)).Infof("cronjob removed")
+	return cronjob
+}
+
 // Return number of jobs
 func (r *Runner) Len() int {
 	return len(r.cron.Entries())
@@ -113,65 +332,458 @@ func (r *Runner) Start() {
 
 // Stop runner
 func (r *Runner) Stop() {
+	r.closeStop()
 	r.cron.Stop()
 	log.Infof("stop runner")
 }
 
+// Stop runner and wait for running jobs, returns false if jobs are still running after timeout
+func (r *Runner) StopAndWait(timeout time.Duration) bool {
+	r.closeStop()
+	ctx := r.cron.Stop()
+	log.Infof("stop runner")
+
+	// scheduled and manual runs
+	done := make(chan struct{})
+	go func() {
+		<-ctx.Done()
+		r.manualRuns.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// close stop channel (once), no manual runs are started afterwards
+func (r *Runner) closeStop() {
+	r.stopOnce.Do(func() {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		close(r.stop)
+	})
+}
+
 // Execute crontab command
 func (r *Runner) cmdFunc(cronjob *CrontabEntry, cmdCallback func(*exec.Cmd) bool) func() {
 	cmdFunc := func() {
-		// fall back to normal shell if not specified
-		taskShell := cronjob.Shell
-		if taskShell == "" {
-			taskShell = DEFAULT_SHELL
+		scheduled := r.cron.Entry(cronjob.EntryId).Prev
+		r.runScheduled(cronjob, cmdCallback, scheduled)
+	}
+	return cmdFunc
+}
+
+// Run scheduled execution of cronjob (jitter, pause and concurrency policy), returns run or reason if skipped
+func (r *Runner) runScheduled(cronjob *CrontabEntry, cmdCallback func(*exec.Cmd) bool, scheduled time.Time) (*JobRun, string) {
+	// skip scheduled execution if paused
+	if r.skipPaused(cronjob) {
+		return nil, "paused"
+	}
+
+	// random start delay (interrupted if runner is stopped)
+	if delay := r.jitterDelay(cronjob); delay > 0 {
+		logFields := LogCronjobToFields(*cronjob)
+		logFields["jitter_s"] = delay.Seconds()
+		log.WithFields(logFields).Infof("delaying start by %v", delay.Round(time.Millisecond))
+
+		if !r.clock.Sleep(delay, r.stop) {
+			r.skip(cronjob, "stopped", "runner stopped during start delay")
+			return nil, "stopped"
+		}
+
+		// pause may be set during start delay
+		if r.skipPaused(cronjob) {
+			return nil, "paused"
 		}
+	}
+
+	// concurrency policy
+	switch cronjob.ConcurrencyPolicy {
+	case CONCURRENCY_POLICY_FORBID:
+		if r.runningCount(cronjob) >= 1 {
+			r.skip(cronjob, "concurrency", "previous execution still running")
+			return nil, "concurrency"
+		}
+	case CONCURRENCY_POLICY_REPLACE:
+		if count := r.killRunning(cronjob); count >= 1 {
+			log.WithFields(LogCronjobToFields(*cronjob)).Infof("replacing %d running executions", count)
+		}
+	}
+
+	run := NewJobRun(cronjob, JOB_TRIGGER_SCHEDULE, scheduled)
+	r.execute(cronjob, cmdCallback, run)
+	return run, ""
+}
+
+// Trigger cronjob manually (outside of schedule), run is executed in background
+func (r *Runner) Trigger(jobId string) (*JobRun, error) {
+	eid, cronjob := r.FindJob(jobId)
+	if cronjob == nil {
+		return nil, fmt.Errorf("job %s not found", jobId)
+	}
+
+	return r.TriggerEntry(eid, JOB_TRIGGER_MANUAL)
+}
+
+// Trigger cronjob entry (outside of schedule), run is executed in background
+func (r *Runner) TriggerEntry(eid cron.EntryID, trigger string) (*JobRun, error) {
+	// lookup and start tracking in one lock (entry may be removed by reload)
+	r.lock.Lock()
+	cronjob, exists := r.cronjobs[eid]
+	cmdCallback := r.cmdCallbacks[eid]
+	if !exists {
+		r.lock.Unlock()
+		return nil, fmt.Errorf("job entry %d not found", eid)
+	}
+
+	// manual runs are tracked for shutdown, not started if runner is stopped
+	stopped := false
+	select {
+	case <-r.stop:
+		stopped = true
+	default:
+		r.manualRuns.Add(1)
+	}
+	r.lock.Unlock()
+
+	run := NewJobRun(cronjob, trigger, time.Time{})
+	jobRuns.Add(run)
+
+	logFields := LogCronjobToFields(*cronjob)
+	logFields["trigger"] = trigger
+	logFields["run"] = run.Result().Id
+	log.WithFields(logFields).Infof("triggered")
+
+	if stopped {
+		log.WithFields(logFields).Warnf("not started, runner stopped")
+		run.finish(r.clock.Now(), -1, JOB_RESULT_ERROR, nil)
+		return run, nil
+	}
+
+	go func() {
+		defer r.manualRuns.Done()
+		r.execute(cronjob, cmdCallback, run)
+	}()
 
-		start := time.Now()
+	return run, nil
+}
 
-		// Init command
-		execCmd := exec.Command(taskShell, "-c", cronjob.Command)
+// Find cronjob by job id or name
+func (r *Runner) FindJob(jobId string) (cron.EntryID, *CrontabEntry) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
 
-		// add custom env to cronjob
-		if len(cronjob.Env) >= 1 {
-			execCmd.Env = append(os.Environ(), cronjob.Env...)
+	for eid, cronjob := range r.cronjobs {
+		if cronjob.Id() == jobId {
+			return eid, cronjob
 		}
+	}
 
-		// exec custom callback
-		if cmdCallback(execCmd) {
+	for eid, cronjob := range r.cronjobs {
+		if cronjob.Name != "" && cronjob.Name == jobId {
+			return eid, cronjob
+		}
+	}
+	return 0, nil
+}
 
-			// exec job
-			cmdStdout, err := execCmd.CombinedOutput()
+// List all cronjobs (sorted by crontab and entry)
+func (r *Runner) Jobs() []*CrontabEntry {
+	var ret []*CrontabEntry
+	entries := r.cron.Entries()
 
-			elapsed := time.Since(start)
+	r.lock.RLock()
+	for _, entry := range entries {
+		if cronjob, exists := r.cronjobs[entry.ID]; exists {
+			ret = append(ret, cronjob)
+		}
+	}
+	r.lock.RUnlock()
 
-			cronjobMetricCommonLables := r.cronjobToPrometheusLabels(*cronjob)
-			prometheusMetricTaskRunDuration.With(cronjobMetricCommonLables).Set(elapsed.Seconds())
-			prometheusMetricTaskRunTime.With(cronjobMetricCommonLables).SetToCurrentTime()
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].CrontabPath != ret[j].CrontabPath {
+			return ret[i].CrontabPath < ret[j].CrontabPath
+		}
+		return ret[i].EntryId < ret[j].EntryId
+	})
+	return ret
+}
 
+// Execute cronjob and update run, metrics and history
+func (r *Runner) execute(cronjob *CrontabEntry, cmdCallback func(*exec.Cmd) bool, run *JobRun) {
+	start := r.clock.Now()
+	run.start(start)
+	scheduled := run.Result().ScheduledTime
+
+	// exec job (failed executions are retried)
+	var (
+		exitCode  int
+		cmdStdout []byte
+		err       error
+	)
+	attempt := 0
+	for ; attempt <= cronjob.Retries; attempt++ {
+		if attempt >= 1 {
 			logFields := LogCronjobToFields(*cronjob)
-			logFields["elapsed_s"] = elapsed.Seconds()
-			if execCmd.ProcessState != nil {
-				logFields["exitCode"] = execCmd.ProcessState.ExitCode()
-			}
+			logFields["attempt"] = attempt + 1
+			log.WithFields(logFields).Warnf("execution failed, retrying (%d of %d retries): %v", attempt, cronjob.Retries, err)
+		}
 
-			if err != nil {
-				prometheusMetricTaskRunCount.With(r.cronjobToPrometheusLabels(*cronjob, prometheus.Labels{"result": "error"})).Inc()
-				prometheusMetricTaskRunResult.With(cronjobMetricCommonLables).Set(0)
-				logFields["result"] = "error"
-			} else {
-				prometheusMetricTaskRunCount.With(r.cronjobToPrometheusLabels(*cronjob, prometheus.Labels{"result": "success"})).Inc()
-				prometheusMetricTaskRunResult.With(cronjobMetricCommonLables).Set(1)
-				logFields["result"] = "success"
+		var replaced bool
+		if cronjob.RunParts != nil {
+			// scripts of run-parts directory one after another
+			exitCode, cmdStdout, replaced, err = r.executeRunParts(cronjob, cmdCallback)
+		} else {
+			execCmd := r.command(cronjob)
+
+			// exec custom callback
+			if !cmdCallback(execCmd) {
+				run.finish(r.clock.Now(), -1, JOB_RESULT_ERROR, nil)
+				return
 			}
 
-			r.updateCronEntryMetrics(cronjob)
-			log.WithFields(logFields).Info("finished")
-			if len(cmdStdout) > 0 {
-				log.Debugln(string(cmdStdout))
-			}
+			execution := r.startRunning(cronjob, execCmd)
+			exitCode, cmdStdout, err = r.executor.Execute(cronjob, execCmd)
+			replaced = r.stopRunning(cronjob, execution)
+		}
+
+		// replaced executions are not retried
+		if err == nil || replaced {
+			break
 		}
 	}
-	return cmdFunc
+
+	elapsed := r.clock.Now().Sub(start)
+
+	cronjobMetricCommonLables := r.cronjobToPrometheusLabels(*cronjob)
+	prometheusMetricTaskRunDuration.With(cronjobMetricCommonLables).Set(elapsed.Seconds())
+	prometheusMetricTaskRunTime.With(cronjobMetricCommonLables).Set(float64(start.Add(elapsed).Unix()))
+
+	logFields := LogCronjobToFields(*cronjob)
+	logFields["elapsed_s"] = elapsed.Seconds()
+	logFields["trigger"] = run.Result().Trigger
+	if run.Result().Trigger == JOB_TRIGGER_SCHEDULE && !scheduled.IsZero() {
+		lateness := start.Sub(scheduled)
+		prometheusMetricTaskRunLateness.With(cronjobMetricCommonLables).Set(lateness.Seconds())
+		logFields["lateness_s"] = lateness.Seconds()
+	}
+	if exitCode >= 0 {
+		logFields["exitCode"] = exitCode
+	}
+	if attempt >= 1 {
+		logFields["retries"] = min(attempt, cronjob.Retries)
+	}
+
+	result := JOB_RESULT_SUCCESS
+	if err != nil {
+		result = JOB_RESULT_ERROR
+		prometheusMetricTaskRunResult.With(cronjobMetricCommonLables).Set(0)
+	} else {
+		prometheusMetricTaskRunResult.With(cronjobMetricCommonLables).Set(1)
+	}
+	prometheusMetricTaskRunCount.With(r.cronjobToPrometheusLabels(*cronjob, prometheus.Labels{"result": result, "trigger": run.Result().Trigger})).Inc()
+	logFields["result"] = result
+
+	r.updateCronEntryMetrics(cronjob)
+	r.recordHistory(cronjob, run.Result().Trigger, scheduled, start, start.Add(elapsed), exitCode, result, cmdStdout)
+	run.finish(start.Add(elapsed), exitCode, result, cmdStdout)
+	log.WithFields(logFields).Info("finished")
+	if len(cmdStdout) > 0 {
+		log.Debugln(string(cmdStdout))
+	}
+
+	// notifications (not for dry-run cronjobs)
+	if r.notifier != nil && !cronjob.DryRun && len(cronjob.Notifications) >= 1 {
+		r.notifier(cronjob, r.apiJob(cronjob), run.Result())
+	}
+}
+
+// Create command of cronjob (shell, env and working directory)
+func (r *Runner) command(cronjob *CrontabEntry) *exec.Cmd {
+	// fall back to normal shell if not specified
+	taskShell := cronjob.Shell
+	if taskShell == "" {
+		taskShell = DEFAULT_SHELL
+	}
+
+	execCmd := exec.Command(taskShell, "-c", cronjob.Command)
+	r.applyCommandEnv(cronjob, execCmd)
+
+	return execCmd
+}
+
+// Apply env and working directory of cronjob to command
+func (r *Runner) applyCommandEnv(cronjob *CrontabEntry, execCmd *exec.Cmd) {
+	// add custom env to cronjob
+	if len(cronjob.Env) >= 1 {
+		execCmd.Env = append(os.Environ(), cronjob.Env...)
+	}
+
+	execCmd.Dir = r.workDir
+	if cronjob.WorkDir != "" {
+		execCmd.Dir = cronjob.WorkDir
+	}
+}
+
+// Running execution of cronjob (command or current script of run-parts sequence)
+type runningExecution struct {
+	lock sync.Mutex
+	cmd  *exec.Cmd
+}
+
+// Set current command of execution
+func (e *runningExecution) setCmd(execCmd *exec.Cmd) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.cmd = execCmd
+}
+
+// Current command of execution (nil between scripts of run-parts sequence)
+func (e *runningExecution) currentCmd() *exec.Cmd {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.cmd
+}
+
+// Track running execution of cronjob
+func (r *Runner) startRunning(cronjob *CrontabEntry, execCmd *exec.Cmd) *runningExecution {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	execution := &runningExecution{cmd: execCmd}
+	if r.running[cronjob] == nil {
+		r.running[cronjob] = map[*runningExecution]bool{}
+	}
+	r.running[cronjob][execution] = true
+	return execution
+}
+
+// Stop tracking of execution, returns true if it was replaced by a newer execution
+func (r *Runner) stopRunning(cronjob *CrontabEntry, execution *runningExecution) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	replaced := !r.running[cronjob][execution]
+	delete(r.running[cronjob], execution)
+	if len(r.running[cronjob]) == 0 {
+		delete(r.running, cronjob)
+	}
+	return replaced
+}
+
+// Check if running execution was replaced by a newer execution
+func (r *Runner) isReplaced(cronjob *CrontabEntry, execution *runningExecution) bool {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return !r.running[cronjob][execution]
+}
+
+// Number of running executions of cronjob
+func (r *Runner) runningCount(cronjob *CrontabEntry) int {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	return len(r.running[cronjob])
+}
+
+// Kill running executions of cronjob, returns number of replaced executions
+func (r *Runner) killRunning(cronjob *CrontabEntry) int {
+	r.lock.Lock()
+	var executions []*runningExecution
+	for execution := range r.running[cronjob] {
+		r.running[cronjob][execution] = false
+		executions = append(executions, execution)
+	}
+	r.lock.Unlock()
+
+	count := 0
+	for _, execution := range executions {
+		// run-parts sequence between scripts, next script is not started
+		execCmd := execution.currentCmd()
+		if execCmd == nil {
+			count++
+			continue
+		}
+
+		if err := r.executor.Kill(execCmd); err != nil {
+			log.WithFields(LogCronjobToFields(*cronjob)).Warnf("cannot kill running execution: %v", err)
+			continue
+		}
+		count++
+	}
+	return count
+}
+
+// Start delay of scheduled run (jitter of cronjob or default jitter), random or stable per host and cronjob
+func (r *Runner) jitterDelay(cronjob *CrontabEntry) time.Duration {
+	maxDelay := r.jitter
+	if cronjob.Jitter != nil {
+		maxDelay = *cronjob.Jitter
+	}
+
+	if maxDelay <= 0 {
+		return 0
+	}
+
+	if r.jitterDeterministic {
+		hostname, _ := os.Hostname()
+		hash := fnv.New64a()
+		hash.Write([]byte(hostname))
+		hash.Write([]byte{0})
+		hash.Write([]byte(cronjob.Id()))
+		return time.Duration(hash.Sum64() % uint64(maxDelay))
+	}
+
+	return time.Duration(rand.Int63n(int64(maxDelay)))
+}
+
+// Skip scheduled execution of cronjob if it is paused, returns true if skipped
+func (r *Runner) skipPaused(cronjob *CrontabEntry) bool {
+	pause := pauses.Find(cronjob)
+	if pause == nil {
+		return false
+	}
+
+	r.skip(cronjob, "paused", "paused by "+pause.String())
+	return true
+}
+
+// Skip scheduled execution of cronjob
+func (r *Runner) skip(cronjob *CrontabEntry, reason, message string) {
+	prometheusMetricTaskRunSkipped.With(r.cronjobToPrometheusLabels(*cronjob, prometheus.Labels{"reason": reason})).Inc()
+	r.updateCronEntryMetrics(cronjob)
+
+	logFields := LogCronjobToFields(*cronjob)
+	logFields["reason"] = reason
+	log.WithFields(logFields).Infof("skipped: %s", message)
+}
+
+// Record execution in history (if enabled)
+func (r *Runner) recordHistory(cronjob *CrontabEntry, trigger string, scheduled, start, end time.Time, exitCode int, result string, output []byte) {
+	if r.history == nil {
+		return
+	}
+
+	err := r.history.Record(HistoryEntry{
+		JobId:         cronjob.Id(),
+		Spec:          cronjob.Spec,
+		User:          cronjob.User,
+		Command:       cronjob.Command,
+		CrontabPath:   cronjob.CrontabPath,
+		Trigger:       trigger,
+		ScheduledTime: scheduled,
+		StartTime:     start,
+		EndTime:       end,
+		ExitCode:      exitCode,
+		Result:        result,
+		Output:        string(output),
+	})
+	if err != nil {
+		log.WithFields(LogCronjobToFields(*cronjob)).Errorf("failed to record execution history: %v", err)
+	}
 }
 
 func (r *Runner) cronjobToPrometheusLabels(cronjob CrontabEntry, additionalLabels ...prometheus.Labels) (labels prometheus.Labels) {
@@ -205,8 +817,54 @@ func (r *Runner) updateCronEntryMetrics(cronjob *CrontabEntry) {
 	}
 }
 
+func (r *Runner) deleteCronjobMetrics(cronjob *CrontabEntry) {
+	// keep metrics if another cronjob uses the same labels
+	for _, other := range r.Jobs() {
+		if other.Spec == cronjob.Spec && other.User == cronjob.User && other.Command == cronjob.Command {
+			return
+		}
+	}
+
+	labels := r.cronjobToPrometheusLabels(*cronjob)
+	prometheusMetricTask.DeletePartialMatch(labels)
+	prometheusMetricTaskRunCount.DeletePartialMatch(labels)
+	prometheusMetricTaskRunResult.DeletePartialMatch(labels)
+	prometheusMetricTaskRunTime.DeletePartialMatch(labels)
+	prometheusMetricTaskRunDuration.DeletePartialMatch(labels)
+	prometheusMetricTaskRunNextTs.DeletePartialMatch(labels)
+	prometheusMetricTaskRunPrevTs.DeletePartialMatch(labels)
+	prometheusMetricTaskRunSkipped.DeletePartialMatch(labels)
+	prometheusMetricTaskPaused.DeletePartialMatch(labels)
+	prometheusMetricTaskRunLateness.DeletePartialMatch(labels)
+	prometheusMetricTaskRunPartsScriptResult.DeletePartialMatch(labels)
+	prometheusMetricTaskRunPartsScriptDuration.DeletePartialMatch(labels)
+}
+
 func (r *Runner) initAllCronEntryMetrics() {
-	for _, cronjob := range r.cronjobs {
+	for _, cronjob := range r.Jobs() {
 		r.updateCronEntryMetrics(cronjob)
 	}
+	r.updatePauseMetrics()
+}
+
+func (r *Runner) updatePauseMetrics() {
+	for _, cronjob := range r.Jobs() {
+		if pauses.Find(cronjob) != nil {
+			prometheusMetricTaskPaused.With(r.cronjobToPrometheusLabels(*cronjob)).Set(1)
+		} else {
+			prometheusMetricTaskPaused.With(r.cronjobToPrometheusLabels(*cronjob)).Set(0)
+		}
+	}
+}
+
+// Build unique identity keys for cronjobs (identical cronjobs are numbered by occurrence)
+func cronjobIdentityKeys(cronjobs []*CrontabEntry) []string {
+	var ret []string
+	occurrences := map[string]int{}
+	for _, cronjob := range cronjobs {
+		id := cronjob.Id()
+		ret = append(ret, fmt.Sprintf("%s/%d", id, occurrences[id]))
+		occurrences[id]++
+	}
+	return ret
 }
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMain(m *testing.M) {
+	initMetrics()
+	os.Exit(m.Run())
+}
+
+// runner with virtual clock and simulation executor (every execution takes duration)
+func newTestRunner(start time.Time, duration time.Duration) (*Runner, *VirtualClock, *SimulationExecutor) {
+	clock := NewVirtualClock(start)
+	executor := &SimulationExecutor{
+		clock:           clock,
+		durations:       map[string]time.Duration{},
+		defaultDuration: duration,
+	}
+
+	runner := NewRunner()
+	runner.clock = clock
+	runner.executor = executor
+	runner.notifier = nil
+
+	return runner, clock, executor
+}
+
+func TestRunnerConcurrencyPolicy(t *testing.T) {
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	to := from.Add(30 * time.Minute)
+
+	type expectedExecution struct {
+		start  string
+		end    string
+		result string
+	}
+
+	tests := []struct {
+		policy          string
+		executions      []expectedExecution
+		skipped         []string
+		peakConcurrency int
+	}{
+		{
+			policy: CONCURRENCY_POLICY_ALLOW,
+			executions: []expectedExecution{
+				{"00:00", "00:12", JOB_RESULT_SUCCESS},
+				{"00:05", "00:17", JOB_RESULT_SUCCESS},
+				{"00:10", "00:22", JOB_RESULT_SUCCESS},
+				{"00:15", "00:27", JOB_RESULT_SUCCESS},
+				{"00:20", "00:32", JOB_RESULT_SUCCESS},
+				{"00:25", "00:37", JOB_RESULT_SUCCESS},
+			},
+			peakConcurrency: 3,
+		},
+		{
+			policy: CONCURRENCY_POLICY_FORBID,
+			executions: []expectedExecution{
+				{"00:00", "00:12", JOB_RESULT_SUCCESS},
+				{"00:15", "00:27", JOB_RESULT_SUCCESS},
+			},
+			skipped:         []string{"00:05", "00:10", "00:20", "00:25"},
+			peakConcurrency: 1,
+		},
+		{
+			policy: CONCURRENCY_POLICY_REPLACE,
+			executions: []expectedExecution{
+				{"00:00", "00:05", JOB_RESULT_ERROR},
+				{"00:05", "00:10", JOB_RESULT_ERROR},
+				{"00:10", "00:15", JOB_RESULT_ERROR},
+				{"00:15", "00:20", JOB_RESULT_ERROR},
+				{"00:20", "00:25", JOB_RESULT_ERROR},
+				{"00:25", "00:37", JOB_RESULT_SUCCESS},
+			},
+			peakConcurrency: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			runner, clock, executor := newTestRunner(from, 12*time.Minute)
+			cronjobs := []CrontabEntry{{
+				Spec:              "*/5 * * * *",
+				User:              "root",
+				Command:           "/usr/local/bin/sync",
+				ConcurrencyPolicy: test.policy,
+			}}
+
+			report := simulate(runner, clock, executor, cronjobs, from, to)
+
+			if len(report.Executions) != len(test.executions) {
+				t.Fatalf("got %d executions, expected %d: %+v", len(report.Executions), len(test.executions), report.Executions)
+			}
+			for i, expected := range test.executions {
+				execution := report.Executions[i]
+				start := execution.StartTime.Format("15:04")
+				end := execution.EndTime.Format("15:04")
+				if start != expected.start || end != expected.end || execution.Result != expected.result {
+					t.Errorf("execution %d: got %s-%s (%s), expected %s-%s (%s)", i, start, end, execution.Result, expected.start, expected.end, expected.result)
+				}
+			}
+
+			if len(report.Skipped) != len(test.skipped) {
+				t.Fatalf("got %d skipped activations, expected %d: %+v", len(report.Skipped), len(test.skipped), report.Skipped)
+			}
+			for i, expected := range test.skipped {
+				skip := report.Skipped[i]
+				if skip.Time.Format("15:04") != expected || skip.Reason != "concurrency" {
+					t.Errorf("skipped %d: got %s (%s), expected %s (concurrency)", i, skip.Time.Format("15:04"), skip.Reason, expected)
+				}
+			}
+
+			if report.PeakConcurrency != test.peakConcurrency {
+				t.Errorf("got peak concurrency %d, expected %d", report.PeakConcurrency, test.peakConcurrency)
+			}
+		})
+	}
+}
+
+func TestRunnerJitter(t *testing.T) {
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	to := from.Add(time.Hour)
+
+	runner, clock, executor := newTestRunner(from, time.Minute)
+	runner.jitter = 10 * time.Minute
+	runner.jitterDeterministic = true
+
+	cronjobs := []CrontabEntry{{
+		Spec:    "*/20 * * * *",
+		User:    "root",
+		Command: "/usr/local/bin/report",
+	}}
+
+	report := simulate(runner, clock, executor, cronjobs, from, to)
+	if len(report.Executions) != 3 {
+		t.Fatalf("got %d executions, expected 3: %+v", len(report.Executions), report.Executions)
+	}
+
+	// deterministic jitter: same delay for every scheduled run of cronjob
+	delay := runner.jitterDelay(&cronjobs[0])
+	for i, execution := range report.Executions {
+		scheduled := from.Add(time.Duration(i) * 20 * time.Minute)
+		if !execution.StartTime.Equal(scheduled.Add(delay)) {
+			t.Errorf("execution %d: started at %s, expected %s (delay %v)", i, execution.StartTime.Format(time.RFC3339), scheduled.Add(delay).Format(time.RFC3339), delay)
+		}
+		if !execution.EndTime.Equal(execution.StartTime.Add(time.Minute)) {
+			t.Errorf("execution %d: finished at %s, expected %s", i, execution.EndTime.Format(time.RFC3339), execution.StartTime.Add(time.Minute).Format(time.RFC3339))
+		}
+	}
+}
+
+func TestRunnerStartDelay(t *testing.T) {
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	callback := func(*exec.Cmd) bool { return true }
+
+	// start scheduled run in background, returns skip reason
+	start := func(runner *Runner, clock *VirtualClock, cronjob *CrontabEntry) <-chan string {
+		result := make(chan string, 1)
+		clock.Go(func() {
+			_, reason := runner.runScheduled(cronjob, callback, from)
+			result <- reason
+		})
+
+		// wait for start delay
+		clock.WaitIdle()
+		return result
+	}
+
+	wait := func(result <-chan string) string {
+		select {
+		case reason := <-result:
+			return reason
+		case <-time.After(5 * time.Second):
+			t.Fatal("scheduled run not finished")
+			return ""
+		}
+	}
+
+	t.Run("paused during delay", func(t *testing.T) {
+		runner, clock, _ := newTestRunner(from, time.Minute)
+		runner.jitter = 10 * time.Minute
+		runner.jitterDeterministic = true
+		cronjob := &CrontabEntry{Spec: "@hourly", User: "root", Command: "/usr/local/bin/paused"}
+
+		result := start(runner, clock, cronjob)
+
+		pauses.Pause(PAUSE_SCOPE_JOB, pauseJobTarget(cronjob), time.Time{})
+		defer pauses.Resume(PAUSE_SCOPE_JOB, pauseJobTarget(cronjob))
+		clock.Advance(10 * time.Minute)
+
+		if reason := wait(result); reason != "paused" {
+			t.Errorf("got skip reason %q, expected paused", reason)
+		}
+	})
+
+	t.Run("stopped during delay", func(t *testing.T) {
+		runner, clock, _ := newTestRunner(from, time.Minute)
+		runner.jitter = 10 * time.Minute
+		runner.jitterDeterministic = true
+		cronjob := &CrontabEntry{Spec: "@hourly", User: "root", Command: "/usr/local/bin/stopped"}
+
+		result := start(runner, clock, cronjob)
+		runner.Stop()
+
+		if reason := wait(result); reason != "stopped" {
+			t.Errorf("got skip reason %q, expected stopped", reason)
+		}
+	})
+}
+
+func TestRunnerTriggerRemoved(t *testing.T) {
+	runner, _, _ := newTestRunner(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Minute)
+	if err := runner.Add(CrontabEntry{Spec: "@hourly", User: "root", Command: "/usr/local/bin/removed"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// job removed by reload between lookup and trigger
+	cronjob := runner.Jobs()[0]
+	eid := cronjob.EntryId
+	runner.Remove(eid)
+
+	if run, err := runner.TriggerEntry(eid, JOB_TRIGGER_MANUAL); err == nil || run != nil {
+		t.Errorf("trigger of removed job should fail")
+	}
+	if _, err := runner.Trigger(cronjob.Id()); err == nil {
+		t.Errorf("trigger of removed job should fail")
+	}
+}
+
+func TestRunnerUpdateKeepsMetrics(t *testing.T) {
+	runner, _, _ := newTestRunner(time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC), time.Minute)
+
+	cronjob := CrontabEntry{Spec: "@hourly", User: "root", Command: "/usr/local/bin/metrics", CrontabPath: "/etc/crontab"}
+	if _, err := runner.Update([]CrontabEntry{cronjob}); err != nil {
+		t.Fatal(err)
+	}
+
+	labels := runner.cronjobToPrometheusLabels(cronjob, prometheus.Labels{"result": JOB_RESULT_SUCCESS, "trigger": JOB_TRIGGER_SCHEDULE})
+	prometheusMetricTaskRunCount.With(labels).Add(3)
+
+	// changed cronjob (same identity and labels, different fingerprint)
+	changed := cronjob
+	changed.Name = "metrics"
+	result, err := runner.Update([]CrontabEntry{changed})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Changed != 1 {
+		t.Fatalf("got %+v, expected one changed cronjob", result)
+	}
+
+	if value := testutil.ToFloat64(prometheusMetricTaskRunCount.With(labels)); value != 3 {
+		t.Errorf("got run count %v after update, expected 3", value)
+	}
+
+	// removed cronjob
+	if _, err := runner.Update(nil); err != nil {
+		t.Fatal(err)
+	}
+	if prometheusMetricTaskRunCount.Delete(labels) {
+		t.Errorf("run count of removed cronjob not deleted")
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	RUN_ONCE_SELECTOR_ALL = "all"
+)
+
+// run selected jobs once (sequentially) and exit with aggregated exit code
+func runOnceCommand() {
+	runner := createCronRunner(opts.Args.Crontabs)
+	currentRunner.Store(runner)
+
+	cronjobs, err := selectRunOnceJobs(runner, opts.Cron.RunOnce, time.Now())
+	if err != nil {
+		log.Fatal(err)
+	}
+	if len(cronjobs) == 0 {
+		log.Fatalf("no jobs selected by %s", strings.Join(opts.Cron.RunOnce, ", "))
+	}
+
+	exitCode := 0
+	failed := 0
+	for _, cronjob := range cronjobs {
+		run, err := runner.TriggerEntry(cronjob.EntryId, JOB_TRIGGER_RUN_ONCE)
+		if err != nil {
+			log.Error(err)
+			failed++
+			if exitCode < 1 {
+				exitCode = 1
+			}
+			continue
+		}
+		<-run.Done()
+
+		result := run.Result()
+		fmt.Print(result.Output)
+
+		if result.Result != JOB_RESULT_SUCCESS {
+			failed++
+			jobExitCode := result.ExitCode
+			if jobExitCode <= 0 {
+				jobExitCode = 1
+			}
+			if jobExitCode > exitCode {
+				exitCode = jobExitCode
+			}
+		}
+	}
+
+	log.Infof("run-once finished: %d jobs executed, %d failed", len(cronjobs), failed)
+	pendingNotifications.Wait()
+	os.Exit(exitCode)
+}
+
+// Select jobs of runner by selectors (jobs are selected only once, in order of runner)
+func selectRunOnceJobs(runner *Runner, selectors []string, now time.Time) ([]*CrontabEntry, error) {
+	var ret []*CrontabEntry
+
+	jobs := runner.Jobs()
+	selected := map[*CrontabEntry]bool{}
+	for _, selector := range selectors {
+		match, err := runOnceSelectorFunc(runner, selector, now)
+		if err != nil {
+			return nil, err
+		}
+
+		count := 0
+		for _, cronjob := range jobs {
+			if match(cronjob) {
+				count++
+				if !selected[cronjob] {
+					selected[cronjob] = true
+					ret = append(ret, cronjob)
+				}
+			}
+		}
+		log.Infof("selector %s matches %d jobs", selector, count)
+	}
+
+	return ret, nil
+}
+
+// Build matching function for selector
+func runOnceSelectorFunc(runner *Runner, selector string, now time.Time) (func(*CrontabEntry) bool, error) {
+	if selector == RUN_ONCE_SELECTOR_ALL {
+		return func(*CrontabEntry) bool { return true }, nil
+	}
+
+	selectorType, value := "", selector
+	if strings.Contains(selector, ":") {
+		split := strings.SplitN(selector, ":", 2)
+		selectorType, value = split[0], split[1]
+	}
+
+	switch selectorType {
+	case "name":
+		return func(cronjob *CrontabEntry) bool {
+			return cronjob.Name == value
+		}, nil
+	case "tag":
+		return func(cronjob *CrontabEntry) bool {
+			return cronjob.HasTag(value)
+		}, nil
+	case "crontab":
+		path := value
+
+		// crontabs from environment variables have no file path
+		if !strings.HasPrefix(path, CRONTAB_ENV_PATH_PREFIX) {
+			var err error
+			if path, err = absConfPath(value); err != nil {
+				return nil, fmt.Errorf("invalid crontab path %s: %w", value, err)
+			}
+		}
+
+		return func(cronjob *CrontabEntry) bool {
+			// run-parts jobs are selected by directory
+			if cronjob.RunParts != nil {
+				return cronjob.RunParts.Directory == path
+			}
+			if cronjob.CrontabPath == "" {
+				return filepath.Dir(cronjob.Command) == path
+			}
+			return cronjob.CrontabPath == path
+		}, nil
+	case "window":
+		from, to, err := parseRunOnceWindow(value, now)
+		if err != nil {
+			return nil, fmt.Errorf("invalid window %s: %w", value, err)
+		}
+
+		return func(cronjob *CrontabEntry) bool {
+			schedule, err := runner.Schedule(cronjob)
+			if err != nil {
+				return false
+			}
+			return len(scheduleActivations(schedule, from, to, 1)) >= 1
+		}, nil
+	case "":
+		return func(cronjob *CrontabEntry) bool {
+			return cronjob.Id() == value || (cronjob.Name != "" && cronjob.Name == value)
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown selector type %s in %s", selectorType, selector)
+	}
+}
+
+// parse time window ("<duration>" for the last duration or "<from>,<to>", times of day are the last occurrence)
+func parseRunOnceWindow(value string, now time.Time) (time.Time, time.Time, error) {
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), now, nil
+	}
+
+	split := strings.SplitN(value, ",", 2)
+	if len(split) != 2 {
+		return time.Time{}, time.Time{}, fmt.Errorf("expected <duration> or <from>,<to>")
+	}
+
+	to, err := parseRelativeTime(split[1], now, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	from, err := parseRelativeTime(split[0], to, true)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start %s is not before end %s", from.Format(LIST_TIME_FORMAT), to.Format(LIST_TIME_FORMAT))
+	}
+
+	return from, to, nil
+}
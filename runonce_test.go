@@ -0,0 +1,49 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRunOnceWindow(t *testing.T) {
+	now := time.Date(2026, 1, 9, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		value string
+		from  string
+		to    string
+	}{
+		{"2h", "2026-01-09T08:00:00Z", "2026-01-09T10:00:00Z"},
+		{"08:00,09:00", "2026-01-09T08:00:00Z", "2026-01-09T09:00:00Z"},
+		{"22:00,06:00", "2026-01-08T22:00:00Z", "2026-01-09T06:00:00Z"},
+		{"09:00,11:00", "2026-01-08T09:00:00Z", "2026-01-08T11:00:00Z"},
+		{"08:00,10:00", "2026-01-09T08:00:00Z", "2026-01-09T10:00:00Z"},
+		{"2026-01-01,2026-01-02", "2026-01-01T00:00:00Z", "2026-01-02T00:00:00Z"},
+		{"2026-01-01T00:00:00Z,2026-01-01 06:00", "2026-01-01T00:00:00Z", "2026-01-01T06:00:00Z"},
+	}
+
+	for _, test := range tests {
+		from, to, err := parseRunOnceWindow(test.value, now)
+		if err != nil {
+			t.Errorf("window %q: %v", test.value, err)
+			continue
+		}
+
+		if from.Format(time.RFC3339) != test.from || to.Format(time.RFC3339) != test.to {
+			t.Errorf("window %q: got %s,%s, expected %s,%s", test.value, from.Format(time.RFC3339), to.Format(time.RFC3339), test.from, test.to)
+		}
+	}
+
+	for _, value := range []string{
+		"",
+		"yesterday",
+		"08:00",
+		"2026-01-02,2026-01-01",
+		"2026-01-01 06:00,2026-01-01 06:00",
+		"08:00,foo",
+	} {
+		if _, _, err := parseRunOnceWindow(value, now); err == nil {
+			t.Errorf("window %q should be invalid", value)
+		}
+	}
+}
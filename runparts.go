@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+// Sequential run-parts execution (all scripts of directory as one cronjob, like run-parts)
+type RunParts struct {
+	Directory string
+
+	// stop at first failing script
+	ExitOnError bool
+
+	// prefix output of scripts with script name
+	Report bool
+}
+
+// Scripts of run-parts directory in lexical order (listed on every execution, subdirectories are ignored like run-parts)
+func (rp *RunParts) Scripts() ([]string, error) {
+	var ret []string
+	err := walkDirectory(rp.Directory, false, map[string]bool{}, executableFiles(func(f os.FileInfo, path string) error {
+		ret = append(ret, path)
+		return nil
+	}))
+	return ret, err
+}
+
+// Execute scripts of run-parts cronjob one after another, returns true if execution was replaced
+func (r *Runner) executeRunParts(cronjob *CrontabEntry, cmdCallback func(*exec.Cmd) bool) (int, []byte, bool, error) {
+	scripts, err := cronjob.RunParts.Scripts()
+	if err != nil {
+		return -1, nil, false, err
+	}
+
+	// whole sequence is one running execution (concurrency policies)
+	execution := r.startRunning(cronjob, nil)
+	defer r.stopRunning(cronjob, execution)
+
+	var (
+		output   bytes.Buffer
+		exitCode int
+		failed   int
+	)
+	for i, script := range scripts {
+		scriptCronjob := *cronjob
+		scriptCronjob.Command = script
+		scriptCronjob.RunParts = nil
+
+		execCmd := exec.Command(script)
+		r.applyCommandEnv(cronjob, execCmd)
+
+		logFields := LogCronjobToFields(*cronjob)
+		logFields["script"] = script
+
+		var (
+			scriptExitCode = -1
+			scriptOutput   []byte
+			scriptErr      error
+			replaced       bool
+		)
+		start := r.clock.Now()
+		if cmdCallback(execCmd) {
+			// command is set before checking for replacement, so it is either killed or not started
+			execution.setCmd(execCmd)
+			if r.isReplaced(cronjob, execution) {
+				return exitCode, output.Bytes(), true, fmt.Errorf("replaced before running %s", script)
+			}
+
+			scriptExitCode, scriptOutput, scriptErr = r.executor.Execute(&scriptCronjob, execCmd)
+			execution.setCmd(nil)
+			replaced = r.isReplaced(cronjob, execution)
+		} else {
+			scriptErr = fmt.Errorf("cannot prepare execution")
+		}
+		elapsed := r.clock.Now().Sub(start)
+
+		if cronjob.RunParts.Report && len(scriptOutput) > 0 {
+			fmt.Fprintf(&output, "%s:\n", script)
+		}
+		output.Write(scriptOutput)
+
+		scriptResult := JOB_RESULT_SUCCESS
+		if scriptErr != nil {
+			scriptResult = JOB_RESULT_ERROR
+		}
+
+		labels := r.cronjobToPrometheusLabels(*cronjob, prometheus.Labels{"script": script})
+		prometheusMetricTaskRunPartsScriptDuration.With(labels).Set(elapsed.Seconds())
+		if scriptErr != nil {
+			prometheusMetricTaskRunPartsScriptResult.With(labels).Set(0)
+		} else {
+			prometheusMetricTaskRunPartsScriptResult.With(labels).Set(1)
+		}
+
+		logFields["elapsed_s"] = elapsed.Seconds()
+		logFields["result"] = scriptResult
+		if scriptExitCode >= 0 {
+			logFields["exitCode"] = scriptExitCode
+		}
+		if scriptErr != nil {
+			log.WithFields(logFields).Warnf("script failed: %v", scriptErr)
+		} else {
+			log.WithFields(logFields).Info("script finished")
+		}
+
+		// remaining scripts are not executed if sequence was replaced
+		if replaced {
+			return scriptExitCode, output.Bytes(), true, fmt.Errorf("replaced while running %s", script)
+		}
+
+		if scriptErr != nil {
+			failed++
+			exitCode = scriptExitCode
+
+			if cronjob.RunParts.ExitOnError {
+				if remaining := len(scripts) - i - 1; remaining >= 1 {
+					log.WithFields(LogCronjobToFields(*cronjob)).Warnf("skipping %d remaining scripts (exit on error)", remaining)
+				}
+				return exitCode, output.Bytes(), false, fmt.Errorf("script %s failed: %w", script, scriptErr)
+			}
+		}
+	}
+
+	if failed >= 1 {
+		return exitCode, output.Bytes(), false, fmt.Errorf("%d of %d scripts failed", failed, len(scripts))
+	}
+
+	return 0, output.Bytes(), false, nil
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// run-parts directory with executable scripts, a script in a subdirectory and a non executable file
+func newTestRunPartsDirectory(t *testing.T) string {
+	dir := t.TempDir()
+
+	for _, name := range []string{"20-second", "10-first"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("not executable\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, "subdir"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "subdir", "30-nested"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+func TestRunPartsScripts(t *testing.T) {
+	dir := newTestRunPartsDirectory(t)
+
+	scripts, err := (&RunParts{Directory: dir}).Scripts()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{filepath.Join(dir, "10-first"), filepath.Join(dir, "20-second")}
+	if len(scripts) != len(expected) {
+		t.Fatalf("got scripts %v, expected %v", scripts, expected)
+	}
+	for i := range expected {
+		if scripts[i] != expected[i] {
+			t.Errorf("got script %s at position %d, expected %s", scripts[i], i, expected[i])
+		}
+	}
+}
+
+func TestRunPartsConcurrencyPolicy(t *testing.T) {
+	from := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Minute)
+	dir := newTestRunPartsDirectory(t)
+
+	tests := []struct {
+		policy     string
+		executions int
+		skipped    int
+		ends       []string
+	}{
+		// sequence of two scripts (2 x 4 minutes) is still running at next activation
+		{CONCURRENCY_POLICY_FORBID, 1, 1, []string{"00:08"}},
+		// sequence is stopped at next activation (second script is killed)
+		{CONCURRENCY_POLICY_REPLACE, 2, 0, []string{"00:05", "00:13"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.policy, func(t *testing.T) {
+			runner, clock, executor := newTestRunner(from, 4*time.Minute)
+			cronjobs := []CrontabEntry{{
+				Spec:              "*/5 * * * *",
+				User:              "root",
+				Command:           "run-parts " + dir,
+				ConcurrencyPolicy: test.policy,
+				RunParts:          &RunParts{Directory: dir},
+			}}
+
+			report := simulate(runner, clock, executor, cronjobs, from, to)
+
+			if len(report.Executions) != test.executions || len(report.Skipped) != test.skipped {
+				t.Fatalf("got %d executions and %d skipped, expected %d and %d", len(report.Executions), len(report.Skipped), test.executions, test.skipped)
+			}
+			for i, end := range test.ends {
+				if got := report.Executions[i].EndTime.Format("15:04"); got != end {
+					t.Errorf("execution %d: finished at %s, expected %s", i, got, end)
+				}
+			}
+		})
+	}
+}
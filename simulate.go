@@ -0,0 +1,398 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SIMULATION_DURATION_DEFAULT = "default"
+	SIMULATION_DURATION_HISTORY = "history"
+)
+
+type SimulationExecution struct {
+	JobId          string        `json:"jobId"`
+	Name           string        `json:"name,omitempty"`
+	User           string        `json:"user"`
+	Command        string        `json:"command"`
+	StartTime      time.Time     `json:"start"`
+	EndTime        time.Time     `json:"end"`
+	Duration       time.Duration `json:"duration"`
+	DurationSource string        `json:"durationSource"`
+	Result         string        `json:"result"`
+}
+
+type SimulationSkip struct {
+	Time    time.Time `json:"time"`
+	JobId   string    `json:"jobId"`
+	Name    string    `json:"name,omitempty"`
+	Command string    `json:"command"`
+	Reason  string    `json:"reason"`
+}
+
+type SimulationOverlap struct {
+	From       time.Time `json:"from"`
+	To         time.Time `json:"to"`
+	JobId      string    `json:"jobId"`
+	OtherJobId string    `json:"otherJobId"`
+}
+
+type SimulationReport struct {
+	From            time.Time             `json:"from"`
+	To              time.Time             `json:"to"`
+	Executions      []SimulationExecution `json:"executions"`
+	Skipped         []SimulationSkip      `json:"skipped"`
+	Overlaps        []SimulationOverlap   `json:"overlaps"`
+	PeakConcurrency int                   `json:"peakConcurrency"`
+	PeakTime        *time.Time            `json:"peakTime,omitempty"`
+}
+
+// Executor which does not execute commands but waits for the expected duration on the virtual clock
+type SimulationExecutor struct {
+	clock           *VirtualClock
+	durations       map[string]time.Duration
+	defaultDuration time.Duration
+
+	lock    sync.Mutex
+	running map[*exec.Cmd]*VirtualTimer
+}
+
+func (e *SimulationExecutor) Execute(cronjob *CrontabEntry, execCmd *exec.Cmd) (int, []byte, error) {
+	duration, _ := e.Duration(cronjob)
+	timer := e.clock.Timer(duration)
+
+	e.lock.Lock()
+	if e.running == nil {
+		e.running = map[*exec.Cmd]*VirtualTimer{}
+	}
+	e.running[execCmd] = timer
+	e.lock.Unlock()
+
+	finished := timer.Wait()
+
+	e.lock.Lock()
+	delete(e.running, execCmd)
+	e.lock.Unlock()
+
+	if !finished {
+		return -1, nil, fmt.Errorf("killed")
+	}
+	return 0, nil, nil
+}
+
+func (e *SimulationExecutor) Kill(execCmd *exec.Cmd) error {
+	e.lock.Lock()
+	timer := e.running[execCmd]
+	e.lock.Unlock()
+
+	if timer == nil {
+		return fmt.Errorf("execution not running")
+	}
+	e.clock.Cancel(timer)
+	return nil
+}
+
+// Expected duration of cronjob and its source (history or default)
+func (e *SimulationExecutor) Duration(cronjob *CrontabEntry) (time.Duration, string) {
+	if duration, exists := e.durations[cronjob.Id()]; exists {
+		return duration, SIMULATION_DURATION_HISTORY
+	}
+	return e.defaultDuration, SIMULATION_DURATION_DEFAULT
+}
+
+// simulate schedule with a virtual clock and print report
+func simulateCommand() {
+	now := time.Now()
+
+	from := now
+	if opts.Simulate.From != "" {
+		var err error
+		if from, err = parseListTime(opts.Simulate.From, now); err != nil {
+			log.Fatalf("invalid --from: %v", err)
+		}
+	}
+
+	to := from.Add(24 * time.Hour)
+	if opts.Simulate.To != "" {
+		var err error
+		if to, err = parseListTime(opts.Simulate.To, from); err != nil {
+			log.Fatalf("invalid --to: %v", err)
+		}
+	}
+
+	clock := NewVirtualClock(from)
+	executor := &SimulationExecutor{
+		clock:           clock,
+		durations:       map[string]time.Duration{},
+		defaultDuration: opts.Simulate.Duration,
+	}
+
+	runner := newRunnerFromOpts()
+	runner.clock = clock
+	runner.executor = executor
+	runner.notifier = nil
+	crontabEntries := loadCommandCrontabs(runner, opts.Simulate.Args.Crontabs)
+
+	// historical average durations
+	if opts.Simulate.History {
+		loadSimulationDurations(executor, crontabEntries)
+	}
+
+	// execution logs only in verbose mode
+	if !opts.Log.Verbose {
+		log.SetLevel(log.WarnLevel)
+	}
+
+	report := simulate(runner, clock, executor, crontabEntries, from, to)
+
+	if opts.Simulate.Json {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	report.Print()
+}
+
+// Simulate all executions of cronjobs inside of time window (executed by runner with virtual clock,
+// including jitter, pauses and concurrency policies)
+func simulate(runner *Runner, clock *VirtualClock, executor *SimulationExecutor, cronjobs []CrontabEntry, from, to time.Time) SimulationReport {
+	type activation struct {
+		time    time.Time
+		cronjob *CrontabEntry
+	}
+
+	type execution struct {
+		cronjob *CrontabEntry
+		run     *JobRun
+	}
+
+	report := SimulationReport{
+		From:       from,
+		To:         to,
+		Executions: []SimulationExecution{},
+		Skipped:    []SimulationSkip{},
+		Overlaps:   []SimulationOverlap{},
+	}
+
+	var activations []activation
+	for i := range cronjobs {
+		schedule, err := runner.Schedule(&cronjobs[i])
+		if err != nil {
+			continue
+		}
+
+		for _, next := range scheduleActivations(schedule, from, to, 0) {
+			activations = append(activations, activation{time: next, cronjob: &cronjobs[i]})
+		}
+	}
+
+	sort.SliceStable(activations, func(i, j int) bool {
+		return activations[i].time.Before(activations[j].time)
+	})
+
+	var (
+		lock       sync.Mutex
+		executions []execution
+	)
+	clock.Set(from)
+	for _, activation := range activations {
+		// finish executions (and start delays) which end before or at activation
+		for next, exists := clock.NextTimer(); exists && !next.After(activation.time); next, exists = clock.NextTimer() {
+			clock.Set(next)
+			clock.WaitIdle()
+		}
+
+		clock.Set(activation.time)
+		cronjob := activation.cronjob
+		scheduled := activation.time
+		clock.Go(func() {
+			run, reason := runner.runScheduled(cronjob, func(*exec.Cmd) bool { return true }, scheduled)
+
+			lock.Lock()
+			defer lock.Unlock()
+			if run != nil {
+				executions = append(executions, execution{cronjob: cronjob, run: run})
+			} else {
+				report.Skipped = append(report.Skipped, SimulationSkip{
+					Time:    scheduled,
+					JobId:   cronjob.Id(),
+					Name:    cronjob.Name,
+					Command: cronjob.Command,
+					Reason:  reason,
+				})
+			}
+		})
+		clock.WaitIdle()
+	}
+
+	// finish remaining executions
+	for next, exists := clock.NextTimer(); exists; next, exists = clock.NextTimer() {
+		clock.Set(next)
+		clock.WaitIdle()
+	}
+
+	for _, execution := range executions {
+		result := execution.run.Result()
+		_, durationSource := executor.Duration(execution.cronjob)
+		report.Executions = append(report.Executions, SimulationExecution{
+			JobId:          execution.cronjob.Id(),
+			Name:           execution.cronjob.Name,
+			User:           execution.cronjob.User,
+			Command:        execution.cronjob.Command,
+			StartTime:      result.StartTime,
+			EndTime:        result.EndTime,
+			Duration:       result.EndTime.Sub(result.StartTime),
+			DurationSource: durationSource,
+			Result:         result.Result,
+		})
+	}
+	sort.SliceStable(report.Executions, func(i, j int) bool {
+		return report.Executions[i].StartTime.Before(report.Executions[j].StartTime)
+	})
+	sort.SliceStable(report.Skipped, func(i, j int) bool {
+		return report.Skipped[i].Time.Before(report.Skipped[j].Time)
+	})
+
+	report.calculateOverlaps()
+	report.calculatePeakConcurrency()
+
+	return report
+}
+
+// find overlapping executions (executions are sorted by start time)
+func (report *SimulationReport) calculateOverlaps() {
+	for i, execution := range report.Executions {
+		for _, other := range report.Executions[i+1:] {
+			if !other.StartTime.Before(execution.EndTime) {
+				break
+			}
+
+			end := execution.EndTime
+			if other.EndTime.Before(end) {
+				end = other.EndTime
+			}
+
+			report.Overlaps = append(report.Overlaps, SimulationOverlap{
+				From:       other.StartTime,
+				To:         end,
+				JobId:      execution.JobId,
+				OtherJobId: other.JobId,
+			})
+		}
+	}
+}
+
+// find maximum number of concurrently running executions
+func (report *SimulationReport) calculatePeakConcurrency() {
+	type event struct {
+		time  time.Time
+		delta int
+	}
+
+	var events []event
+	for _, execution := range report.Executions {
+		if !execution.EndTime.After(execution.StartTime) {
+			continue
+		}
+		events = append(events, event{execution.StartTime, 1}, event{execution.EndTime, -1})
+	}
+
+	// executions ending at the same time as others start are not concurrent
+	sort.SliceStable(events, func(i, j int) bool {
+		if events[i].time.Equal(events[j].time) {
+			return events[i].delta < events[j].delta
+		}
+		return events[i].time.Before(events[j].time)
+	})
+
+	concurrency := 0
+	for _, event := range events {
+		concurrency += event.delta
+		if concurrency > report.PeakConcurrency {
+			report.PeakConcurrency = concurrency
+			peakTime := event.time
+			report.PeakTime = &peakTime
+		}
+	}
+}
+
+// Print report as tables
+func (report *SimulationReport) Print() {
+	writer := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintf(writer, "simulation from %s to %s\n\n", report.From.Format(LIST_TIME_FORMAT), report.To.Format(LIST_TIME_FORMAT))
+
+	fmt.Fprintf(writer, "EXECUTIONS (%d)\n", len(report.Executions))
+	fmt.Fprintln(writer, "START\tEND\tDURATION\tRESULT\tID\tNAME\tUSER\tCOMMAND")
+	for _, execution := range report.Executions {
+		duration := execution.Duration.String()
+		if execution.DurationSource == SIMULATION_DURATION_HISTORY {
+			duration += " (history)"
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n", execution.StartTime.Format(LIST_TIME_FORMAT), execution.EndTime.Format(LIST_TIME_FORMAT), duration, execution.Result, execution.JobId, execution.Name, execution.User, execution.Command)
+	}
+
+	fmt.Fprintf(writer, "\nSKIPPED (%d)\n", len(report.Skipped))
+	fmt.Fprintln(writer, "TIME\tREASON\tID\tNAME\tCOMMAND")
+	for _, skip := range report.Skipped {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\t%s\n", skip.Time.Format(LIST_TIME_FORMAT), skip.Reason, skip.JobId, skip.Name, skip.Command)
+	}
+
+	fmt.Fprintf(writer, "\nOVERLAPS (%d)\n", len(report.Overlaps))
+	fmt.Fprintln(writer, "FROM\tTO\tID\tOVERLAPPING ID")
+	for _, overlap := range report.Overlaps {
+		fmt.Fprintf(writer, "%s\t%s\t%s\t%s\n", overlap.From.Format(LIST_TIME_FORMAT), overlap.To.Format(LIST_TIME_FORMAT), overlap.JobId, overlap.OtherJobId)
+	}
+
+	if report.PeakTime != nil {
+		fmt.Fprintf(writer, "\npeak concurrency: %d (at %s)\n", report.PeakConcurrency, report.PeakTime.Format(LIST_TIME_FORMAT))
+	} else {
+		fmt.Fprintf(writer, "\npeak concurrency: %d\n", report.PeakConcurrency)
+	}
+
+	if err := writer.Flush(); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// load average durations of cronjobs from execution history
+func loadSimulationDurations(executor *SimulationExecutor, cronjobs []CrontabEntry) {
+	if opts.Cron.StateDir == "" {
+		log.Fatal("--history requires --state-dir")
+	}
+
+	stateDir, err := absConfPath(opts.Cron.StateDir)
+	if err != nil {
+		log.Fatalf("invalid state directory %s: %v", opts.Cron.StateDir, err)
+	}
+
+	executionHistory, err := OpenHistoryReadOnly(stateDir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer executionHistory.Close()
+
+	for _, cronjob := range cronjobs {
+		duration, count, err := executionHistory.AverageDuration(cronjob.Id())
+		if err != nil {
+			log.Fatal(err)
+		}
+
+		if count >= 1 {
+			executor.durations[cronjob.Id()] = duration
+			log.WithFields(LogCronjobToFields(cronjob)).Debugf("using average duration %v of %d executions", duration, count)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Spool directories of user crontabs (file name is user) used by distribution
+var SPOOL_DIRS = []string{
+	// Debian family
+	"/var/spool/cron/crontabs",
+	// SuSE
+	"/var/spool/cron/tabs",
+	// RedHat family (cronie)
+	"/var/spool/cron",
+}
+
+// Find spool directory of system (first existing default spool directory)
+func detectSpoolDir() string {
+	for _, path := range SPOOL_DIRS {
+		if checkIfDirectoryExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// Include user crontabs of spool directories (file name is user, file has to be owned by user)
+func includeSpoolDirectories(paths []string) ([]CrontabEntry, error) {
+	var ret []CrontabEntry
+
+	for _, path := range paths {
+		entries, err := includeSpoolDirectory(path)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, entries...)
+	}
+
+	return ret, nil
+}
+
+func includeSpoolDirectory(path string) ([]CrontabEntry, error) {
+	var ret []CrontabEntry
+
+	path, err := absConfPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid spool directory %s: %w", path, err)
+	}
+
+	if !checkIfDirectoryExists(path) {
+		log.Infof("path %s does not exists\n", path)
+		return ret, nil
+	}
+
+	dirEntries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// schedules are validated per crontab (same parser as runner)
+	validator := newRunnerFromOpts()
+
+	for _, dirEntry := range dirEntries {
+		crontabPath := filepath.Join(path, dirEntry.Name())
+		username := dirEntry.Name()
+
+		// temporary and backup files
+		if strings.HasPrefix(username, ".") || strings.HasPrefix(username, "#") || strings.HasSuffix(username, "~") {
+			log.Debugf("ignoring temporary file %s", crontabPath)
+			continue
+		}
+
+		// symlinks and hard links could point to crontabs of other users
+		f, err := os.Lstat(crontabPath)
+		if err != nil {
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			if !f.IsDir() {
+				log.Infof("ignoring spool crontab %s: not a regular file\n", crontabPath)
+			}
+			continue
+		}
+		stat, ok := f.Sys().(*syscall.Stat_t)
+		if !ok {
+			log.Infof("ignoring spool crontab %s: cannot detect link count\n", crontabPath)
+			continue
+		}
+		if stat.Nlink > 1 {
+			log.Infof("ignoring spool crontab %s: hard linked file\n", crontabPath)
+			continue
+		}
+
+		uid, err := spoolUserUid(username)
+		if err != nil {
+			log.Infof("ignoring spool crontab %s: %v\n", crontabPath, err)
+			continue
+		}
+
+		// file owner has to match user (instead of owners of trust policy)
+		policy := *filePolicy
+		policy.Owners = []uint32{uid}
+		if err := policy.CheckFile(f); err != nil {
+			log.Infof("ignoring spool crontab %s: %v\n", crontabPath, err)
+			continue
+		}
+
+		// invalid user crontabs are skipped (like traditional cron), other crontabs are still loaded
+		entries, err := parseCrontab(crontabPath, username)
+		if err == nil {
+			err = validator.Validate(entries)
+		}
+		if err != nil {
+			log.Errorf("ignoring spool crontab %s: %v\n", crontabPath, err)
+			continue
+		}
+		ret = append(ret, entries...)
+	}
+
+	return ret, nil
+}
+
+// uid of user of spool crontab
+func spoolUserUid(username string) (uint32, error) {
+	u, err := user.Lookup(username)
+	if err != nil {
+		return 0, fmt.Errorf("unknown user %s", username)
+	}
+
+	uid, err := strconv.ParseUint(u.Uid, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid uid of user %s: %w", username, err)
+	}
+	return uint32(uid), nil
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	log "github.com/sirupsen/logrus"
+)
+
+type fileState struct {
+	modTime time.Time
+	size    int64
+	mode    os.FileMode
+}
+
+// Watcher for crontab files and directories, triggers reload on changes
+type Watcher struct {
+	paths    []string
+	debounce time.Duration
+	interval time.Duration
+	reload   chan<- string
+
+	lock    sync.Mutex
+	changed map[string]bool
+	timer   *time.Timer
+}
+
+func NewWatcher(paths []string, debounce, interval time.Duration, reload chan<- string) *Watcher {
+	w := &Watcher{
+		debounce: debounce,
+		interval: interval,
+		reload:   reload,
+		changed:  map[string]bool{},
+	}
+
+	for _, path := range paths {
+		if absPath, err := absConfPath(path); err == nil {
+			w.paths = append(w.paths, absPath)
+		}
+	}
+
+	return w
+}
+
+// Start watching (inotify, falls back to polling if not available)
+func (w *Watcher) Start(poll bool) {
+	if !poll {
+		err := w.startNotify()
+		if err == nil {
+			log.Infof("watching %d paths for changes (inotify)", len(w.paths))
+			return
+		}
+		log.Warnf("cannot watch paths with inotify, falling back to polling: %v", err)
+	}
+
+	w.startPolling()
+	log.Infof("watching %d paths for changes (polling every %v)", len(w.paths), w.interval)
+}
+
+func (w *Watcher) startNotify() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	w.addNotifyWatches(watcher)
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if !w.isRelevant(event.Name) {
+					continue
+				}
+
+				// watch new directories (and replaced ConfigMap data directories)
+				if event.Has(fsnotify.Create) {
+					if stat, err := os.Stat(event.Name); err == nil && stat.IsDir() {
+						w.addNotifyWatchRecursive(watcher, event.Name)
+					}
+				}
+
+				w.notify(event.Name)
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				log.Warnf("watcher error: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+func (w *Watcher) addNotifyWatches(watcher *fsnotify.Watcher) {
+	for _, path := range w.paths {
+		stat, err := os.Stat(path)
+		if err == nil && stat.IsDir() {
+			w.addNotifyWatchRecursive(watcher, path)
+			continue
+		}
+
+		// files (or not existing paths) are watched by parent directory to detect atomic replacements
+		if err := watcher.Add(filepath.Dir(path)); err != nil {
+			log.Warnf("cannot watch %s: %v", filepath.Dir(path), err)
+		}
+	}
+}
+
+func (w *Watcher) addNotifyWatchRecursive(watcher *fsnotify.Watcher, path string) {
+	for _, dir := range findDirectories(path) {
+		if err := watcher.Add(dir); err != nil {
+			log.Warnf("cannot watch %s: %v", dir, err)
+		}
+	}
+}
+
+// check if event path is a watched path (or inside of a watched directory)
+func (w *Watcher) isRelevant(path string) bool {
+	for _, watchPath := range w.paths {
+		if path == watchPath || strings.HasPrefix(path, watchPath+string(os.PathSeparator)) {
+			return true
+		}
+
+		// Kubernetes ConfigMap updates are symlink swaps of ..data inside the parent directory
+		if filepath.Dir(path) == filepath.Dir(watchPath) && strings.HasPrefix(filepath.Base(path), "..") {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *Watcher) startPolling() {
+	go func() {
+		previous := w.snapshot()
+		for range time.Tick(w.interval) {
+			current := w.snapshot()
+
+			for path, state := range current {
+				if prevState, exists := previous[path]; !exists || prevState != state {
+					w.notify(path)
+				}
+			}
+
+			for path := range previous {
+				if _, exists := current[path]; !exists {
+					w.notify(path)
+				}
+			}
+
+			previous = current
+		}
+	}()
+}
+
+// state of all watched files (symlinks are resolved)
+func (w *Watcher) snapshot() map[string]fileState {
+	ret := map[string]fileState{}
+	for _, path := range w.paths {
+		for _, dir := range append(findDirectories(path), path) {
+			entries, err := os.ReadDir(dir)
+			if err != nil {
+				entries = nil
+			}
+
+			files := []string{dir}
+			for _, entry := range entries {
+				files = append(files, filepath.Join(dir, entry.Name()))
+			}
+
+			for _, file := range files {
+				if stat, err := os.Stat(file); err == nil && !stat.IsDir() {
+					ret[file] = fileState{modTime: stat.ModTime(), size: stat.Size(), mode: stat.Mode()}
+				}
+			}
+		}
+	}
+	return ret
+}
+
+// collect changed path and trigger (debounced) reload
+func (w *Watcher) notify(path string) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	log.Debugf("detected change of %s", path)
+	w.changed[path] = true
+
+	if w.timer != nil {
+		w.timer.Stop()
+	}
+	w.timer = time.AfterFunc(w.debounce, w.trigger)
+}
+
+func (w *Watcher) trigger() {
+	w.lock.Lock()
+	var changed []string
+	for path := range w.changed {
+		changed = append(changed, path)
+	}
+	w.changed = map[string]bool{}
+	w.lock.Unlock()
+
+	sort.Strings(changed)
+	log.WithField("files", strings.Join(changed, ",")).Infof("detected changes of %d files", len(changed))
+
+	// non blocking, a pending reload also covers these changes
+	select {
+	case w.reload <- "file change":
+	default:
+	}
+}
+
+// collect all paths which should be watched (crontabs, includes, spool and run-parts directories)
+func collectWatchPaths(args []string) []string {
+	var ret []string
+
+	if opts.Cron.Auto {
+		ret = append(ret, "/etc/crontab", "/etc/crontabs", "/etc/cron.d")
+
+		if spoolDir := detectSpoolDir(); spoolDir != "" && len(opts.Cron.SpoolDir) == 0 {
+			ret = append(ret, spoolDir)
+		}
+	}
+
+	for _, crontabPath := range args {
+		if !isRemoteCrontab(crontabPath) && strings.Contains(crontabPath, ":") {
+			crontabPath = strings.SplitN(crontabPath, ":", 2)[1]
+		}
+
+		// remote crontabs are polled
+		if isRemoteCrontab(crontabPath) {
+			continue
+		}
+		ret = append(ret, crontabPath)
+	}
+
+	ret = append(ret, opts.Cron.IncludeCronD...)
+	ret = append(ret, opts.Cron.SpoolDir...)
+
+	for _, runPart := range opts.Cron.RunParts {
+		if strings.Contains(runPart, ":") {
+			ret = append(ret, runPartsPath(strings.SplitN(runPart, ":", 2)[1]))
+		}
+	}
+
+	for _, runParts := range [][]string{
+		opts.Cron.RunParts1m,
+		opts.Cron.RunParts15m,
+		opts.Cron.RunPartsHourly,
+		opts.Cron.RunPartsDaily,
+		opts.Cron.RunPartsWeekly,
+		opts.Cron.RunPartsMonthly,
+	} {
+		for _, path := range runParts {
+			ret = append(ret, runPartsPath(path))
+		}
+	}
+
+	return ret
+}
+
+// strip optional user from run-parts path (user:path)
+func runPartsPath(path string) string {
+	if strings.Contains(path, ":") {
+		return strings.SplitN(path, ":", 2)[1]
+	}
+	return path
+}
+
+// find directory and all sub directories (follows symlinks, skips ".." data directories)
+func findDirectories(path string) []string {
+	var ret []string
+
+	if stat, err := os.Stat(path); err != nil || !stat.IsDir() {
+		return ret
+	}
+
+	visited := map[string]bool{}
+	var walk func(dir string)
+	walk = func(dir string) {
+		resolvedDir, err := filepath.EvalSymlinks(dir)
+		if err != nil || visited[resolvedDir] {
+			return
+		}
+		visited[resolvedDir] = true
+		ret = append(ret, dir)
+
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			log.Warnf("cannot read directory %s: %v", dir, err)
+			return
+		}
+
+		for _, entry := range entries {
+			entryPath := filepath.Join(dir, entry.Name())
+			if strings.HasPrefix(entry.Name(), "..") {
+				continue
+			}
+
+			if stat, err := os.Stat(entryPath); err == nil && stat.IsDir() {
+				walk(entryPath)
+			}
+		}
+	}
+	walk(path)
+
+	return ret
+}